@@ -0,0 +1,127 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxBatchLength is the number of calls batch requests are limited to by default.
+const DefaultMaxBatchLength = 100
+
+// BatchCodec is a Codec whose requests may carry several calls, e.g. JSON-RPC batch requests.
+// Every call is served as a separate request, and their responses are written together.
+type BatchCodec interface {
+	Codec
+	// SplitBatch returns a request per call of a batch request, whose GetBody returns the call
+	// again. ok is false if the request has a single call, its body is then left unread.
+	// err is written as the response to the whole batch, e.g. if it has more than
+	// maxLength calls. maxLength is less than 1 if the length is not limited.
+	SplitBatch(r *http.Request, maxLength int) (calls []*http.Request, ok bool, err error)
+	// WriteBatch writes the responses of the calls, in the order of the calls.
+	// Calls without a response have an empty one.
+	WriteBatch(w http.ResponseWriter, resps [][]byte) error
+}
+
+// ServeBatch serves the calls of a batch request of up to maxLength calls with handler,
+// up to concurrency of them at once, one at a time if it is less than 2, and writes
+// their responses with a codec built by newCodec. It returns false, leaving the request
+// to be served as usual, if the codec is not a BatchCodec or the request has a single call.
+// The context of the calls is marked with InBatch. A call whose handler panics
+// gets an Internal error as its response.
+func ServeBatch(w http.ResponseWriter, r *http.Request, newCodec CodecBuilder, handler http.Handler, concurrency, maxLength int) bool {
+	bc, ok := newCodec().(BatchCodec)
+	if !ok {
+		return false
+	}
+
+	calls, ok, err := bc.SplitBatch(r, maxLength)
+	if !ok {
+		return false
+	}
+	if err != nil {
+		bc.WriteError(w, err)
+		return true
+	}
+
+	resps := make([][]byte, len(calls))
+	serve := func(i int) {
+		call := calls[i].WithContext(context.WithValue(calls[i].Context(), batchKey{}, true))
+		rw := &batchResponseWriter{header: make(http.Header)}
+		defer func() {
+			if p := recover(); p != nil {
+				rw = &batchResponseWriter{header: make(http.Header)}
+				writeCallPanic(rw, call, newCodec(), p)
+			}
+			resps[i] = rw.body.Bytes()
+		}()
+		handler.ServeHTTP(rw, call)
+	}
+
+	if concurrency < 2 {
+		for i := range calls {
+			serve(i)
+		}
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, concurrency)
+		for i := range calls {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(i int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				serve(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	bc.WriteBatch(w, resps)
+
+	return true
+}
+
+// writeCallPanic writes the Internal error of a call whose handler panicked with c,
+// reading the call again for codecs which keep its state, e.g. the ID of a JSON-RPC call.
+func writeCallPanic(w http.ResponseWriter, call *http.Request, c Codec, p interface{}) {
+	if call.GetBody != nil {
+		if body, err := call.GetBody(); err == nil {
+			call.Body = body
+			// the route is read for the state of the call only
+			c.Route(call)
+		}
+	}
+	c.WriteError(w, status.Errorf(codes.Internal, "panic: %v", p))
+}
+
+type batchKey struct{}
+
+// InBatch reports whether the request is a call of a batch request served by ServeBatch.
+func InBatch(ctx context.Context) bool {
+	inBatch, _ := ctx.Value(batchKey{}).(bool)
+	return inBatch
+}
+
+// batchResponseWriter keeps the response to a call of a batch request.
+// The status and the headers are dropped, as the batch has a single set of them.
+type batchResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *batchResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *batchResponseWriter) WriteHeader(statusCode int) {}
@@ -0,0 +1,170 @@
+package codec
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// batchHandler answers every JSON-RPC call with its params and whether it is served in a batch.
+// It panics on calls whose params have "panic" set.
+var batchHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	c := NewJsonRPCCodec()
+	if _, err := c.Route(r); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var params map[string]interface{}
+	if err := c.ReadRequest(r, &params); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if params["panic"] == true {
+		panic("boom")
+	}
+	params["inBatch"] = InBatch(r.Context())
+	c.WriteResponse(w, params)
+})
+
+func TestServeBatch(t *testing.T) {
+	jsonRPC := func() Codec { return NewJsonRPCCodec() }
+
+	for _, tc := range []struct {
+		name        string
+		newCodec    CodecBuilder
+		concurrency int
+		maxLength   int
+		req         string
+		// want is the response, empty if the request is not served as a batch
+		want string
+	}{
+		{
+			name:     "not a batch codec",
+			newCodec: func() Codec { return NewRESTCCodec() },
+			req:      `[{"jsonrpc": "2.0", "method": "m", "id": 1}]`,
+		},
+		{
+			name:     "single call",
+			newCodec: jsonRPC,
+			req:      `{"jsonrpc": "2.0", "method": "m", "id": 1}`,
+		},
+		{
+			name:     "batch",
+			newCodec: jsonRPC,
+			req: `[
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 1}, "id": 1},
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 2}},
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 3}, "id": 3}
+			]`,
+			want: `[
+				{"jsonrpc": "2.0", "result": {"n": 1, "inBatch": true}, "id": 1},
+				{"jsonrpc": "2.0", "result": {"n": 3, "inBatch": true}, "id": 3}
+			]`,
+		},
+		{
+			name:        "concurrent batch",
+			newCodec:    jsonRPC,
+			concurrency: 2,
+			req: `[
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 1}, "id": 1},
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 2}, "id": 2},
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 3}, "id": 3}
+			]`,
+			want: `[
+				{"jsonrpc": "2.0", "result": {"n": 1, "inBatch": true}, "id": 1},
+				{"jsonrpc": "2.0", "result": {"n": 2, "inBatch": true}, "id": 2},
+				{"jsonrpc": "2.0", "result": {"n": 3, "inBatch": true}, "id": 3}
+			]`,
+		},
+		{
+			name:     "panic",
+			newCodec: jsonRPC,
+			req: `[
+				{"jsonrpc": "2.0", "method": "m", "params": {"panic": true}, "id": 1},
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 2}, "id": 2}
+			]`,
+			want: `[
+				{"jsonrpc": "2.0", "error": {"code": -32603}, "id": 1},
+				{"jsonrpc": "2.0", "result": {"n": 2, "inBatch": true}, "id": 2}
+			]`,
+		},
+		{
+			name:        "concurrent panic",
+			newCodec:    jsonRPC,
+			concurrency: 2,
+			req: `[
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 1}, "id": 1},
+				{"jsonrpc": "2.0", "method": "m", "params": {"panic": true}, "id": 2}
+			]`,
+			want: `[
+				{"jsonrpc": "2.0", "result": {"n": 1, "inBatch": true}, "id": 1},
+				{"jsonrpc": "2.0", "error": {"code": -32603}, "id": 2}
+			]`,
+		},
+		{
+			name:      "at most max length calls",
+			newCodec:  jsonRPC,
+			maxLength: 2,
+			req: `[
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 1}, "id": 1},
+				{"jsonrpc": "2.0", "method": "m", "params": {"n": 2}, "id": 2}
+			]`,
+			want: `[
+				{"jsonrpc": "2.0", "result": {"n": 1, "inBatch": true}, "id": 1},
+				{"jsonrpc": "2.0", "result": {"n": 2, "inBatch": true}, "id": 2}
+			]`,
+		},
+		{
+			name:      "more than max length calls",
+			newCodec:  jsonRPC,
+			maxLength: 2,
+			req:       `[{"jsonrpc": "2.0", "method": "m", "id": 1}, {"jsonrpc": "2.0", "method": "m", "id": 2}, {"jsonrpc": "2.0", "method": "m", "id": 3}]`,
+			want:      `{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			served := ServeBatch(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.req)), tc.newCodec, batchHandler, tc.concurrency, tc.maxLength)
+
+			if tc.want == "" {
+				if served {
+					t.Fatalf("want the request not to be served, got %d %s", rec.Code, rec.Body)
+				}
+				return
+			}
+			if !served {
+				t.Fatal("want the request to be served as a batch")
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%v: %s", err, rec.Body)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(errorCodes(got), want) {
+				t.Fatalf("want %s, got %s", tc.want, rec.Body)
+			}
+		})
+	}
+}
+
+// errorCodes removes everything but the codes of the errors of JSON-RPC responses.
+func errorCodes(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, resp := range v {
+			errorCodes(resp)
+		}
+	case map[string]interface{}:
+		if e, ok := v["error"].(map[string]interface{}); ok {
+			delete(e, "message")
+			delete(e, "data")
+		}
+	}
+	return v
+}
@@ -0,0 +1,136 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ClientCodec is the client-side counterpart of Codec: it writes requests
+// the way the Codec reads them and reads responses the way it writes them.
+type ClientCodec interface {
+	// NewRequest returns a request calling the route of the router served at baseURL with in.
+	NewRequest(ctx context.Context, baseURL, route string, in interface{}) (*http.Request, error)
+	// ReadResponse decodes the response into out or returns the error the server has written.
+	ReadResponse(resp *http.Response, out interface{}) error
+}
+
+// Invoke calls the route of the router served at baseURL and decodes the response into out.
+func Invoke(ctx context.Context, client *http.Client, cdc ClientCodec, baseURL, route string, in, out interface{}) error {
+	resp, err := do(ctx, client, cdc, baseURL, route, in, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return cdc.ReadResponse(resp, out)
+}
+
+// InvokeStream calls the server-streaming route of the router served at baseURL
+// asking for newline-delimited JSON. Every line of the response is decoded with the codec.
+func InvokeStream(ctx context.Context, client *http.Client, cdc ClientCodec, baseURL, route string, in interface{}) (grpc.ClientStream, error) {
+	resp, err := do(ctx, client, cdc, baseURL, route, in, http.Header{"Accept": {runtime.ContentTypeNDJSON}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientStream{
+		ctx:  ctx,
+		resp: resp,
+		cdc:  cdc,
+		body: bufio.NewReader(resp.Body),
+	}, nil
+}
+
+func do(ctx context.Context, client *http.Client, cdc ClientCodec, baseURL, route string, in interface{}, header http.Header) (*http.Response, error) {
+	req, err := cdc.NewRequest(ctx, baseURL, route, in)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		runtime.AddMetadata(req.Header, runtime.MetadataHeaderPrefix, md)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		switch ctx.Err() {
+		case context.Canceled:
+			return nil, status.Error(codes.Canceled, err.Error())
+		case context.DeadlineExceeded:
+			return nil, status.Error(codes.DeadlineExceeded, err.Error())
+		}
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	return resp, nil
+}
+
+// clientStream implements grpc.ClientStream on top of a streamed HTTP response.
+type clientStream struct {
+	ctx  context.Context
+	resp *http.Response
+	cdc  ClientCodec
+	body *bufio.Reader
+}
+
+// Header returns the metadata sent by the server as Grpc-Metadata-* headers.
+func (s *clientStream) Header() (metadata.MD, error) {
+	return runtime.MetadataFromHeader(s.resp.Header, runtime.MetadataHeaderPrefix), nil
+}
+
+// Trailer returns the metadata sent by the server as Grpc-Trailer-* HTTP trailers,
+// which are only known once RecvMsg has returned io.EOF.
+func (s *clientStream) Trailer() metadata.MD {
+	return runtime.MetadataFromHeader(s.resp.Trailer, runtime.MetadataTrailerPrefix)
+}
+
+// CloseSend does nothing as the request has already been sent.
+func (s *clientStream) CloseSend() error {
+	return nil
+}
+
+func (s *clientStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg fails as the request message has already been sent.
+func (s *clientStream) SendMsg(m interface{}) error {
+	return status.Error(codes.Unimplemented, "request message has already been sent")
+}
+
+// RecvMsg decodes the next line of the response into m.
+// It returns io.EOF when the response is over.
+func (s *clientStream) RecvMsg(m interface{}) error {
+	for {
+		line, err := s.body.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				s.resp.Body.Close()
+				if err != io.EOF && s.ctx.Err() != nil {
+					return status.Error(codes.Canceled, s.ctx.Err().Error())
+				}
+				return err
+			}
+			continue
+		}
+
+		lineResp := new(http.Response)
+		*lineResp = *s.resp
+		lineResp.Body = ioutil.NopCloser(bytes.NewReader(line))
+		lineResp.ContentLength = int64(len(line))
+
+		return s.cdc.ReadResponse(lineResp, m)
+	}
+}
@@ -2,6 +2,10 @@ package codec
 
 import (
 	"net/http"
+
+	"github.com/golang/protobuf/jsonpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Codec interface {
@@ -11,4 +15,91 @@ type Codec interface {
 	WriteError(w http.ResponseWriter, err error) error
 }
 
+// CodecBuilder returns a new codec. Routers build one per request, since a codec may keep
+// the state of the request it reads, e.g. the ID of a JSON-RPC call.
 type CodecBuilder func() Codec
+
+// RouteNotFoundError is the error routers write for requests whose route has no handler.
+// It is a NotFound gRPC error, which JsonRPCCodec writes as an E_NO_METHOD one.
+type RouteNotFoundError struct {
+	Route string
+}
+
+func (e *RouteNotFoundError) Error() string {
+	return "no handler for route " + e.Route
+}
+
+// GRPCStatus returns the NotFound status of the error.
+func (e *RouteNotFoundError) GRPCStatus() *status.Status {
+	return status.New(codes.NotFound, e.Error())
+}
+
+// StreamInBatchError is the error routers write for calls of batch requests to streaming methods,
+// whose responses cannot be part of the batch. It is an InvalidArgument gRPC error,
+// which JsonRPCCodec writes as an E_INVALID_REQ one.
+type StreamInBatchError struct {
+	Route string
+}
+
+func (e *StreamInBatchError) Error() string {
+	return "streaming route " + e.Route + " cannot be called in a batch"
+}
+
+// GRPCStatus returns the InvalidArgument status of the error.
+func (e *StreamInBatchError) GRPCStatus() *status.Status {
+	return status.New(codes.InvalidArgument, e.Error())
+}
+
+// jsonOptions configure how RESTCodec and JsonRPCCodec read and write messages.
+type jsonOptions struct {
+	marshaler   jsonpb.Marshaler
+	unmarshaler jsonpb.Unmarshaler
+}
+
+func newJSONOptions() jsonOptions {
+	return jsonOptions{
+		unmarshaler: jsonpb.Unmarshaler{AllowUnknownFields: true},
+	}
+}
+
+// jsonOption is an option of both RESTCodec and JsonRPCCodec.
+type jsonOption func(*jsonOptions)
+
+func (o jsonOption) applyREST(opts *restOptions) {
+	o(&opts.jsonOptions)
+}
+
+func (o jsonOption) applyJsonRPC(opts *jsonRPCOptions) {
+	o(&opts.jsonOptions)
+}
+
+// WithEmitDefaults writes fields with zero values, which are left out by default.
+// Generated API descriptions and clients treat every field as optional, so they read either.
+func WithEmitDefaults() jsonOption {
+	return func(opts *jsonOptions) {
+		opts.marshaler.EmitDefaults = true
+	}
+}
+
+// WithOrigName writes fields with their names in the .proto file rather than their JSON names,
+// e.g. page_size rather than pageSize. Requests are read with either of them regardless.
+func WithOrigName() jsonOption {
+	return func(opts *jsonOptions) {
+		opts.marshaler.OrigName = true
+	}
+}
+
+// WithEnumsAsInts writes enum values as numbers rather than as their names.
+// Generated API descriptions and clients still describe enums by their names.
+func WithEnumsAsInts() jsonOption {
+	return func(opts *jsonOptions) {
+		opts.marshaler.EnumsAsInts = true
+	}
+}
+
+// WithRejectUnknown rejects requests with unknown fields, which are ignored by default.
+func WithRejectUnknown() jsonOption {
+	return func(opts *jsonOptions) {
+		opts.unmarshaler.AllowUnknownFields = false
+	}
+}
@@ -0,0 +1,18 @@
+package codec
+
+import "context"
+
+type codecKey struct{}
+
+// NewContext returns a copy of ctx carrying the codec a router read the route of a request with.
+// Codecs such as JsonRPCCodec keep the state of the request they read, so the request must be
+// handled with that same codec.
+func NewContext(ctx context.Context, c Codec) context.Context {
+	return context.WithValue(ctx, codecKey{}, c)
+}
+
+// FromContext returns the codec stored in ctx by NewContext.
+func FromContext(ctx context.Context) (Codec, bool) {
+	c, ok := ctx.Value(codecKey{}).(Codec)
+	return c, ok
+}
@@ -0,0 +1,98 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// Field is a single field of a message, read from the body of a request or written as the
+// response on its own, as the body and the response_body of google.api.http bindings define.
+// It is encoded the way the message encodes it in the proto3 JSON mapping, so that e.g.
+// 64-bit integers are strings and enums are named whatever the type of the field.
+type Field struct {
+	// Message is the message holding the field.
+	Message proto.Message
+	// Name is the name of the field in the .proto file, e.g. "page_size".
+	Name string
+	// JSONName is the JSON name of the field, e.g. "pageSize".
+	JSONName string
+}
+
+// marshalJSON encodes v in the proto3 JSON mapping if it is a message or a Field,
+// and with encoding/json otherwise.
+func marshalJSON(m *jsonpb.Marshaler, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case proto.Message:
+		var buf bytes.Buffer
+		if err := m.Marshal(&buf, v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Field:
+		return marshalField(m, v)
+	}
+	return json.Marshal(v)
+}
+
+// marshalField encodes the field as the member of the encoded message. A field with
+// the default value is written as such even if the marshaler leaves defaults out,
+// and as null if it is not set, e.g. another field of its oneof is.
+func marshalField(m *jsonpb.Marshaler, f Field) ([]byte, error) {
+	name := f.JSONName
+	if m.OrigName {
+		name = f.Name
+	}
+
+	for _, emitDefaults := range []bool{m.EmitDefaults, true} {
+		fm := *m
+		fm.EmitDefaults = emitDefaults
+		data, err := marshalJSON(&fm, f.Message)
+		if err != nil {
+			return nil, err
+		}
+
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(data, &members); err != nil {
+			return nil, err
+		}
+		if member, ok := members[name]; ok {
+			return member, nil
+		}
+	}
+	return []byte("null"), nil
+}
+
+// unmarshalJSON decodes the JSON value read from r into v in the proto3 JSON mapping
+// if v is a message or a Field, and with encoding/json otherwise. It returns io.EOF if r is empty.
+func unmarshalJSON(u *jsonpb.Unmarshaler, r io.Reader, v interface{}) error {
+	switch v := v.(type) {
+	case proto.Message:
+		return u.Unmarshal(r, v)
+	case Field:
+		return unmarshalField(u, r, v)
+	}
+	return json.NewDecoder(r).Decode(v)
+}
+
+// unmarshalField decodes the JSON value read from r as the member of the message
+// named after the field, merging it into the message.
+func unmarshalField(u *jsonpb.Unmarshaler, r io.Reader, f Field) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+
+	message, err := json.Marshal(map[string]json.RawMessage{f.Name: data})
+	if err != nil {
+		return err
+	}
+	return u.Unmarshal(bytes.NewReader(message), f.Message)
+}
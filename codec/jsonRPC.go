@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 
+	"github.com/golang/protobuf/jsonpb"
 	"github.com/sourcegraph/jsonrpc2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // define list of json-rpc v2 error code
@@ -20,106 +25,326 @@ const (
 	E_SERVER      int64 = -32000
 )
 
-type jsonRPCoptions struct {
+// jsonRPCOptions configure JsonRPCCodec.
+type jsonRPCOptions struct {
+	jsonOptions
 	errorClassifier func(error) int64
 }
 
-type jsonRPCoption func(*jsonRPCoptions)
+// jsonRPCOption is an option of JsonRPCCodec.
+type jsonRPCOption interface {
+	applyJsonRPC(*jsonRPCOptions)
+}
+
+type jsonRPCOptionFunc func(*jsonRPCOptions)
+
+func (f jsonRPCOptionFunc) applyJsonRPC(opts *jsonRPCOptions) {
+	f(opts)
+}
 
-func WithErrorClassifier(errorClassifier func(error) int64) jsonRPCoption {
-	return func(opts *jsonRPCoptions) {
+// WithErrorClassifier sets the function returning the code of the JSON-RPC error
+// an error is written with by JsonRPCCodec, JsonRPCCodeFromError by default or if it is nil.
+func WithErrorClassifier(errorClassifier func(error) int64) jsonRPCOption {
+	return jsonRPCOptionFunc(func(opts *jsonRPCOptions) {
 		opts.errorClassifier = errorClassifier
-	}
+	})
 }
 
+// JsonRPCCodec reads and writes messages in the proto3 JSON mapping
+// as the params and the results of JSON-RPC 2.0 calls. It keeps the call read by Route
+// to answer it, so it serves a single request and must not be shared.
+// Calls without an ID are notifications: nothing is written in response to them.
 type JsonRPCCodec struct {
-	jsonrpcRequest  *jsonrpc2.Request
+	call            *jsonrpcCall
 	errorClassifier func(error) int64
+	marshaler       jsonpb.Marshaler
+	unmarshaler     jsonpb.Unmarshaler
+}
+
+// jsonrpcCall is a JSON-RPC 2.0 request object. The ID is kept as it is sent,
+// it is nil for notifications.
+type jsonrpcCall struct {
+	Method string
+	Params json.RawMessage
+	ID     json.RawMessage
+	Meta   json.RawMessage
 }
 
-func NewJsonRPCCodec(opts ...jsonRPCoption) Codec {
-	defaultOptions := &jsonRPCoptions{}
+// jsonrpcResponse is a JSON-RPC 2.0 response object. The ID is null if the request
+// could not be read.
+type jsonrpcResponse struct {
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpc2.Error `json:"error,omitempty"`
+	Meta    json.RawMessage `json:"meta,omitempty"`
+	JSONRPC string          `json:"jsonrpc"`
+}
 
+func NewJsonRPCCodec(opts ...jsonRPCOption) Codec {
+	defaultOptions := &jsonRPCOptions{
+		jsonOptions:     newJSONOptions(),
+		errorClassifier: JsonRPCCodeFromError,
+	}
 	for _, opt := range opts {
-		opt(defaultOptions)
+		opt.applyJsonRPC(defaultOptions)
 	}
 
 	return &JsonRPCCodec{
-		jsonrpcRequest:  &jsonrpc2.Request{},
 		errorClassifier: defaultOptions.errorClassifier,
+		marshaler:       defaultOptions.marshaler,
+		unmarshaler:     defaultOptions.unmarshaler,
 	}
 }
 
+// Route returns the method of the call. Bodies which are not JSON are rejected
+// with E_PARSE, and JSON values which are not valid request objects with E_INVALID_REQ.
 func (c *JsonRPCCodec) Route(r *http.Request) (route string, err error) {
-	defer r.Body.Close()
-
 	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if !json.Valid(body) {
+		return "", &jsonrpc2.Error{Code: E_PARSE, Message: "parse error"}
+	}
+
+	call, err := parseJsonRPCCall(body)
 	if err != nil {
-		return "", nil
+		return "", &jsonrpc2.Error{Code: E_INVALID_REQ, Message: "invalid request: " + err.Error()}
+	}
+	c.call = call
+
+	return call.Method, nil
+}
+
+// parseJsonRPCCall reads a request object as the specification defines it.
+func parseJsonRPCCall(body []byte) (*jsonrpcCall, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return nil, errors.New("not an object")
+	}
+
+	if version := members["jsonrpc"]; string(version) != `"2.0"` {
+		return nil, errors.New(`jsonrpc must be "2.0"`)
+	}
+
+	call := &jsonrpcCall{
+		Meta: members["meta"],
+	}
+	if err := json.Unmarshal(members["method"], &call.Method); err != nil || call.Method == "" {
+		return nil, errors.New("method must be a non-empty string")
 	}
 
-	c.jsonrpcRequest.UnmarshalJSON(body)
+	if params, ok := members["params"]; ok {
+		if len(params) == 0 || (params[0] != '{' && params[0] != '[') {
+			return nil, errors.New("params must be an object or an array")
+		}
+		call.Params = params
+	}
 
-	r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	if id, ok := members["id"]; ok {
+		switch id[0] {
+		case '"', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			// a string, null or a number
+		default:
+			return nil, errors.New("id must be a string, a number or null")
+		}
+		call.ID = id
+	}
 
-	return c.jsonrpcRequest.Method, nil
+	return call, nil
 }
 
+// ReadRequest reads the params of the call into out. Calls without params leave
+// the message empty, params which do not fit it are rejected with E_BAD_PARAMS.
 func (c *JsonRPCCodec) ReadRequest(r *http.Request, out interface{}) error {
-	if c.jsonrpcRequest.Params == nil {
-		return errors.New("request contains nil params")
+	if c.call == nil || c.call.Params == nil {
+		return nil
+	}
+
+	if err := unmarshalJSON(&c.unmarshaler, bytes.NewReader(c.call.Params), out); err != nil {
+		return &jsonrpc2.Error{Code: E_BAD_PARAMS, Message: "invalid params: " + err.Error()}
 	}
 
-	var (
-		paramsBytes = []byte(*c.jsonrpcRequest.Params)
-		decoder     = json.NewDecoder(ioutil.NopCloser(bytes.NewBuffer(paramsBytes)))
-	)
+	return nil
+}
 
-	return decoder.Decode(out)
+// CheckClientStream rejects the calls of client and bidirectional streaming methods with E_INVALID_REQ:
+// the body of a request is a single call, whose params are the only request message.
+func (c *JsonRPCCodec) CheckClientStream(r *http.Request) error {
+	return &jsonrpc2.Error{Code: E_INVALID_REQ, Message: "client streaming methods cannot be called over JSON-RPC"}
 }
 
 func (c *JsonRPCCodec) WriteResponse(w http.ResponseWriter, grpcResp interface{}) error {
-	grpcRespBin, err := json.Marshal(grpcResp)
+	if c.notification() {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	grpcRespBin, err := marshalJSON(&c.marshaler, grpcResp)
 	if err != nil {
 		return err
 	}
 
-	finResp := &jsonrpc2.Response{
-		ID:     c.jsonrpcRequest.ID,
-		Meta:   c.jsonrpcRequest.Meta,
-		Result: (*json.RawMessage)(&grpcRespBin),
+	return c.write(w, &jsonrpcResponse{Result: grpcRespBin})
+}
+
+// WriteError writes the error as a JSON-RPC error with the gRPC status of the error as its data,
+// and the code returned by the error classifier, JsonRPCCodeFromError if it is nil.
+// A *jsonrpc2.Error, e.g. one about a malformed request, is written as it is.
+func (c *JsonRPCCodec) WriteError(w http.ResponseWriter, err error) error {
+	if c.notification() {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	rpcErr, ok := err.(*jsonrpc2.Error)
+	if !ok {
+		rpcErr = &jsonrpc2.Error{
+			Message: err.Error(),
+		}
+		switch err.(type) {
+		case *RouteNotFoundError:
+			rpcErr.Code = E_NO_METHOD
+		case *StreamInBatchError:
+			rpcErr.Code = E_INVALID_REQ
+		default:
+			rpcErr.Code = JsonRPCCodeFromError(err)
+			if c.errorClassifier != nil {
+				rpcErr.Code = c.errorClassifier(err)
+			}
+		}
+		// the gRPC status of the error, with its details
+		rpcErr.SetError(newErrorStatus(err))
+	}
+
+	return c.write(w, &jsonrpcResponse{Error: rpcErr})
+}
+
+// notification reports whether the call read by Route is a notification.
+func (c *JsonRPCCodec) notification() bool {
+	return c.call != nil && c.call.ID == nil
+}
+
+// write writes the response to the call read by Route, with a null ID if there is none.
+func (c *JsonRPCCodec) write(w http.ResponseWriter, resp *jsonrpcResponse) error {
+	resp.JSONRPC = "2.0"
+	if c.call != nil {
+		resp.ID = c.call.ID
+		resp.Meta = c.call.Meta
 	}
 
-	finRespBin, err := finResp.MarshalJSON()
+	bResp, err := json.Marshal(resp)
 	if err != nil {
 		return err
 	}
-	_, err = w.Write(finRespBin)
+	_, err = w.Write(bResp)
 
 	return err
 }
 
-func (c *JsonRPCCodec) WriteError(w http.ResponseWriter, err error) error {
-	finResp := &jsonrpc2.Response{
-		ID:   c.jsonrpcRequest.ID,
-		Meta: c.jsonrpcRequest.Meta,
-		Error: &jsonrpc2.Error{
-			Message: err.Error(),
-		},
+// JsonRPCCodeFromError returns the code of the JSON-RPC error corresponding to the gRPC status code
+// of the error: E_BAD_PARAMS for InvalidArgument, E_NO_METHOD for Unimplemented, E_INTERNAL for Internal,
+// and E_SERVER minus the gRPC code for the others, e.g. -32005 for NotFound, in the range reserved
+// for server errors. Errors without a gRPC status are Unknown ones, -32002.
+func JsonRPCCodeFromError(err error) int64 {
+	switch code := status.Code(err); code {
+	case codes.InvalidArgument:
+		return E_BAD_PARAMS
+	case codes.Unimplemented:
+		return E_NO_METHOD
+	case codes.Internal:
+		return E_INTERNAL
+	default:
+		return E_SERVER - int64(code)
 	}
+}
 
-	if c.errorClassifier != nil {
-		finResp.Error.Code = c.errorClassifier(err)
-	} else {
-		finResp.Error.Code = E_INTERNAL
+// CodeFromJsonRPCError returns the gRPC status code a JSON-RPC error code most likely corresponds to.
+// It is the inverse of JsonRPCCodeFromError, errors of the protocol itself are InvalidArgument ones
+// and the codes of other errors are Unknown.
+func CodeFromJsonRPCError(code int64) codes.Code {
+	switch {
+	case code == E_BAD_PARAMS, code == E_PARSE, code == E_INVALID_REQ:
+		return codes.InvalidArgument
+	case code == E_NO_METHOD:
+		return codes.Unimplemented
+	case code == E_INTERNAL:
+		return codes.Internal
+	case code < E_SERVER && code >= E_SERVER-int64(codes.Unauthenticated):
+		return codes.Code(E_SERVER - code)
+	default:
+		return codes.Unknown
 	}
+}
 
-	bResp, err := finResp.MarshalJSON()
+// SplitBatch returns a request per call of a JSON-RPC batch request, a JSON array of calls.
+// Every request has the call as its body and the headers and the context of the batch.
+// Empty batches and batches of more than maxLength calls are rejected with E_INVALID_REQ.
+func (c *JsonRPCCodec) SplitBatch(r *http.Request, maxLength int) (calls []*http.Request, ok bool, err error) {
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
 	if err != nil {
-		// ?
+		return nil, true, err
 	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
 
-	_, err = w.Write(bResp)
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, false, nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, true, &jsonrpc2.Error{Code: E_PARSE, Message: err.Error()}
+	}
+	if len(batch) == 0 {
+		return nil, true, &jsonrpc2.Error{Code: E_INVALID_REQ, Message: "empty batch"}
+	}
+	if maxLength > 0 && len(batch) > maxLength {
+		return nil, true, &jsonrpc2.Error{
+			Code:    E_INVALID_REQ,
+			Message: fmt.Sprintf("batch of %d calls, at most %d are allowed", len(batch), maxLength),
+		}
+	}
+
+	calls = make([]*http.Request, len(batch))
+	for i, call := range batch {
+		call := call
+		calls[i] = r.WithContext(r.Context())
+		calls[i].Body = ioutil.NopCloser(bytes.NewReader(call))
+		calls[i].ContentLength = int64(len(call))
+		calls[i].GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(call)), nil
+		}
+	}
+
+	return calls, true, nil
+}
+
+// WriteBatch writes the responses of the calls as a JSON array. Nothing is written
+// if none of the calls has a response, e.g. if all of them are notifications.
+func (c *JsonRPCCodec) WriteBatch(w http.ResponseWriter, resps [][]byte) error {
+	var buf bytes.Buffer
+	for _, resp := range resps {
+		if len(resp) == 0 {
+			continue
+		}
+		if buf.Len() == 0 {
+			buf.WriteByte('[')
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.Write(bytes.TrimSpace(resp))
+	}
+	if buf.Len() == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	buf.WriteByte(']')
+
+	_, err := w.Write(buf.Bytes())
 
 	return err
 }
@@ -0,0 +1,90 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/sourcegraph/jsonrpc2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// JsonRPCClientCodec is the client-side counterpart of JsonRPCCodec.
+// It posts a JSON-RPC 2.0 request whose method is the route to baseURL,
+// with the request message in the proto3 JSON mapping as the params.
+// Unknown fields of results are ignored.
+type JsonRPCClientCodec struct {
+	lastID      uint64
+	marshaler   jsonpb.Marshaler
+	unmarshaler jsonpb.Unmarshaler
+}
+
+func NewJsonRPCClientCodec() ClientCodec {
+	return &JsonRPCClientCodec{
+		unmarshaler: jsonpb.Unmarshaler{AllowUnknownFields: true},
+	}
+}
+
+func (c *JsonRPCClientCodec) NewRequest(ctx context.Context, baseURL, route string, in interface{}) (*http.Request, error) {
+	params, err := marshalJSON(&c.marshaler, in)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonrpcRequest := &jsonrpc2.Request{
+		Method: route,
+		Params: (*json.RawMessage)(&params),
+		ID:     jsonrpc2.ID{Num: atomic.AddUint64(&c.lastID, 1)},
+	}
+	body, err := jsonrpcRequest.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, baseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req.WithContext(ctx), nil
+}
+
+func (c *JsonRPCClientCodec) ReadResponse(resp *http.Response, out interface{}) error {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	jsonrpcResponse := &jsonrpc2.Response{}
+	if err := jsonrpcResponse.UnmarshalJSON(body); err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return status.Error(codes.Unknown, fmt.Sprintf("unexpected HTTP status %s", resp.Status))
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if e := jsonrpcResponse.Error; e != nil {
+		var st errorStatus
+		if e.Data == nil || json.Unmarshal(*e.Data, &st) != nil || st.Code == codes.OK {
+			// errors of servers which do not send the gRPC status
+			return status.Error(CodeFromJsonRPCError(e.Code), e.Message)
+		}
+		return st.err()
+	}
+	if jsonrpcResponse.Result == nil {
+		return status.Error(codes.Internal, "response contains nil result")
+	}
+
+	if err := unmarshalJSON(&c.unmarshaler, bytes.NewReader(*jsonrpcResponse.Result), out); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
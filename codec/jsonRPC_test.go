@@ -0,0 +1,167 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestJsonRPCCodeFromError(t *testing.T) {
+	for _, tc := range []struct {
+		code codes.Code
+		want int64
+	}{
+		{codes.Canceled, -32001},
+		{codes.Unknown, -32002},
+		{codes.InvalidArgument, E_BAD_PARAMS},
+		{codes.NotFound, -32005},
+		{codes.PermissionDenied, -32007},
+		{codes.Unimplemented, E_NO_METHOD},
+		{codes.Internal, E_INTERNAL},
+		{codes.Unavailable, -32014},
+		{codes.Unauthenticated, -32016},
+	} {
+		t.Run(tc.code.String(), func(t *testing.T) {
+			got := JsonRPCCodeFromError(status.Error(tc.code, "error"))
+			if got != tc.want {
+				t.Fatalf("want %d, got %d", tc.want, got)
+			}
+			if code := CodeFromJsonRPCError(got); code != tc.code {
+				t.Fatalf("want %d to be mapped back to %v, got %v", got, tc.code, code)
+			}
+		})
+	}
+
+	if got := JsonRPCCodeFromError(errors.New("plain error")); got != -32002 {
+		t.Fatalf("want errors without a status to be Unknown ones, -32002, got %d", got)
+	}
+}
+
+func TestCodeFromJsonRPCError(t *testing.T) {
+	for _, tc := range []struct {
+		code int64
+		want codes.Code
+	}{
+		{E_PARSE, codes.InvalidArgument},
+		{E_INVALID_REQ, codes.InvalidArgument},
+		{E_BAD_PARAMS, codes.InvalidArgument},
+		{E_NO_METHOD, codes.Unimplemented},
+		{E_INTERNAL, codes.Internal},
+		{E_SERVER, codes.Unknown},
+		{-32017, codes.Unknown},
+		{1, codes.Unknown},
+	} {
+		if got := CodeFromJsonRPCError(tc.code); got != tc.want {
+			t.Errorf("%d: want %v, got %v", tc.code, tc.want, got)
+		}
+	}
+}
+
+func TestJsonRPCErrorClassifier(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []jsonRPCOption
+		want int64
+	}{
+		{
+			name: "default",
+			want: -32005,
+		},
+		{
+			name: "classifier",
+			opts: []jsonRPCOption{WithErrorClassifier(func(error) int64 { return 42 })},
+			want: 42,
+		},
+		{
+			name: "nil classifier",
+			opts: []jsonRPCOption{WithErrorClassifier(nil)},
+			want: -32005,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewJsonRPCCodec(tc.opts...)
+			if _, err := c.Route(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc": "2.0", "method": "m", "id": 1}`))); err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			c.WriteError(rec, status.Error(codes.NotFound, "not found"))
+
+			var got struct {
+				Error struct {
+					Code int64 `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%v: %s", err, rec.Body)
+			}
+			if got.Error.Code != tc.want {
+				t.Fatalf("want %d, got %d", tc.want, got.Error.Code)
+			}
+		})
+	}
+}
+
+// TestJsonRPCClientStream checks that client streams are rejected, as the body of the request
+// is a single call and not newline-delimited request messages.
+func TestJsonRPCClientStream(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		newCodec CodecBuilder
+		req      string
+		// want is the response, empty if the stream is opened
+		want string
+	}{
+		{
+			name:     "REST codec",
+			newCodec: func() Codec { return NewRESTCCodec() },
+			req:      "{\"n\": 1}\n{\"n\": 2}\n",
+		},
+		{
+			name:     "JSON-RPC codec",
+			newCodec: func() Codec { return NewJsonRPCCodec() },
+			req:      `{"jsonrpc": "2.0", "method": "m", "params": {"n": 1}, "id": 7}`,
+			want:     `{"jsonrpc": "2.0", "error": {"code": -32600}, "id": 7}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.newCodec()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.req))
+			if _, err := c.Route(r); err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			_, err := runtime.NewClientStream(rec, r, c, "/Service/m")
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("want the stream to be opened, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("want the stream to be rejected")
+			}
+			c.WriteError(rec, err)
+
+			var got, want interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%v: %s", err, rec.Body)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(errorCodes(got), want) {
+				t.Fatalf("want %s, got %s", tc.want, rec.Body)
+			}
+		})
+	}
+}
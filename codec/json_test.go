@@ -0,0 +1,208 @@
+package codec
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/api/distribution"
+	"google.golang.org/genproto/googleapis/api/label"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFieldRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// field is read into a new message and written from want
+		field func(msg proto.Message) Field
+		empty proto.Message
+		body  string
+		want  proto.Message
+	}{
+		{
+			name: "repeated messages",
+			field: func(msg proto.Message) Field {
+				return Field{Message: msg, Name: "additional_bindings", JSONName: "additionalBindings"}
+			},
+			empty: &annotations.HttpRule{},
+			body:  `[{"get": "/a"}, {"post": "/b", "body": "*"}]`,
+			want: &annotations.HttpRule{AdditionalBindings: []*annotations.HttpRule{
+				{Pattern: &annotations.HttpRule_Get{Get: "/a"}},
+				{Pattern: &annotations.HttpRule_Post{Post: "/b"}, Body: "*"},
+			}},
+		},
+		{
+			name: "map",
+			field: func(msg proto.Message) Field {
+				return Field{Message: msg, Name: "metadata", JSONName: "metadata"}
+			},
+			empty: &errdetails.ErrorInfo{},
+			body:  `{"a": "1", "b": "2"}`,
+			want:  &errdetails.ErrorInfo{Metadata: map[string]string{"a": "1", "b": "2"}},
+		},
+		{
+			name: "repeated int64",
+			field: func(msg proto.Message) Field {
+				return Field{Message: msg, Name: "bucket_counts", JSONName: "bucketCounts"}
+			},
+			empty: &distribution.Distribution{},
+			body:  `["1", "-9223372036854775808"]`,
+			want:  &distribution.Distribution{BucketCounts: []int64{1, -9223372036854775808}},
+		},
+		{
+			name: "int64",
+			field: func(msg proto.Message) Field {
+				return Field{Message: msg, Name: "count", JSONName: "count"}
+			},
+			empty: &distribution.Distribution{},
+			body:  `"9007199254740993"`,
+			want:  &distribution.Distribution{Count: 9007199254740993},
+		},
+		{
+			name: "enum",
+			field: func(msg proto.Message) Field {
+				return Field{Message: msg, Name: "value_type", JSONName: "valueType"}
+			},
+			empty: &label.LabelDescriptor{},
+			body:  `"INT64"`,
+			want:  &label.LabelDescriptor{ValueType: label.LabelDescriptor_INT64},
+		},
+		{
+			name: "oneof",
+			field: func(msg proto.Message) Field {
+				return Field{Message: msg, Name: "get", JSONName: "get"}
+			},
+			empty: &annotations.HttpRule{},
+			body:  `"/a"`,
+			want:  &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/a"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cdc := NewRESTCCodec()
+
+			got := proto.Clone(tc.empty)
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+			if err := cdc.ReadRequest(r, tc.field(got)); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+
+			w := httptest.NewRecorder()
+			if err := cdc.WriteResponse(w, tc.field(tc.want)); err != nil {
+				t.Fatal(err)
+			}
+			assertJSON(t, w.Body.String(), tc.body)
+		})
+	}
+}
+
+func TestFieldDefaults(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		opts  []restOption
+		field Field
+		want  string
+	}{
+		{
+			name:  "zero value",
+			field: Field{Message: &distribution.Distribution{}, Name: "count", JSONName: "count"},
+			want:  `"0"`,
+		},
+		{
+			name:  "zero enum",
+			field: Field{Message: &label.LabelDescriptor{}, Name: "value_type", JSONName: "valueType"},
+			want:  `"STRING"`,
+		},
+		{
+			name:  "empty repeated field",
+			field: Field{Message: &annotations.HttpRule{}, Name: "additional_bindings", JSONName: "additionalBindings"},
+			want:  `[]`,
+		},
+		{
+			name:  "unset oneof",
+			field: Field{Message: &annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/b"}}, Name: "get", JSONName: "get"},
+			want:  `null`,
+		},
+		{
+			name:  "orig name",
+			opts:  []restOption{WithOrigName()},
+			field: Field{Message: &label.LabelDescriptor{ValueType: label.LabelDescriptor_BOOL}, Name: "value_type", JSONName: "valueType"},
+			want:  `"BOOL"`,
+		},
+		{
+			name:  "enum as int",
+			opts:  []restOption{WithEnumsAsInts()},
+			field: Field{Message: &label.LabelDescriptor{ValueType: label.LabelDescriptor_BOOL}, Name: "value_type", JSONName: "valueType"},
+			want:  `1`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if err := NewRESTCCodec(tc.opts...).WriteResponse(w, tc.field); err != nil {
+				t.Fatal(err)
+			}
+			assertJSON(t, w.Body.String(), tc.want)
+		})
+	}
+}
+
+func TestFieldEmptyBody(t *testing.T) {
+	want := &annotations.HttpRule{Body: "*"}
+	got := proto.Clone(want)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(" "))
+	if err := NewRESTCCodec().ReadRequest(r, Field{Message: got, Name: "get", JSONName: "get"}); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	body := `{"key": "k", "unknown": 1}`
+
+	for _, tc := range []struct {
+		name string
+		opts []restOption
+		code codes.Code
+	}{
+		{name: "ignored by default", code: codes.OK},
+		{name: "rejected", opts: []restOption{WithRejectUnknown()}, code: codes.InvalidArgument},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var msg label.LabelDescriptor
+			r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+			err := NewRESTCCodec(tc.opts...).ReadRequest(r, &msg)
+			if code := status.Code(err); code != tc.code {
+				t.Fatalf("want %v, got %v", tc.code, err)
+			}
+			if err == nil && msg.Key != "k" {
+				t.Fatalf("want the known fields, got %v", &msg)
+			}
+		})
+	}
+}
+
+// assertJSON fails the test unless got and want are the same JSON value.
+func assertJSON(t *testing.T, got, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal([]byte(got), &gotValue); err != nil {
+		t.Fatalf("want JSON, got %q: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+}
@@ -2,13 +2,62 @@ package codec
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-type RESTCodec struct{}
+// restOptions configure RESTCodec.
+type restOptions struct {
+	jsonOptions
+	errorClassifier func(error) int
+}
+
+// restOption is an option of RESTCodec.
+type restOption interface {
+	applyREST(*restOptions)
+}
+
+type restOptionFunc func(*restOptions)
+
+func (f restOptionFunc) applyREST(opts *restOptions) {
+	f(opts)
+}
+
+// WithRESTErrorClassifier sets the function returning the HTTP status an error is written
+// with by RESTCodec, HTTPStatusFromError by default.
+func WithRESTErrorClassifier(errorClassifier func(error) int) restOption {
+	return restOptionFunc(func(opts *restOptions) {
+		opts.errorClassifier = errorClassifier
+	})
+}
+
+// RESTCodec reads and writes messages in the proto3 JSON mapping.
+type RESTCodec struct {
+	errorClassifier func(error) int
+	marshaler       jsonpb.Marshaler
+	unmarshaler     jsonpb.Unmarshaler
+}
+
+func NewRESTCCodec(opts ...restOption) Codec {
+	defaultOptions := &restOptions{
+		jsonOptions:     newJSONOptions(),
+		errorClassifier: HTTPStatusFromError,
+	}
+	for _, opt := range opts {
+		opt.applyREST(defaultOptions)
+	}
 
-func NewRESTCCodec() Codec {
-	return &RESTCodec{}
+	return &RESTCodec{
+		errorClassifier: defaultOptions.errorClassifier,
+		marshaler:       defaultOptions.marshaler,
+		unmarshaler:     defaultOptions.unmarshaler,
+	}
 }
 
 func (c *RESTCodec) Route(r *http.Request) (route string, err error) {
@@ -16,17 +65,29 @@ func (c *RESTCodec) Route(r *http.Request) (route string, err error) {
 }
 
 func (c *RESTCodec) ReadRequest(r *http.Request, out interface{}) error {
-	var (
-		decoder = json.NewDecoder(r.Body)
-		err     = decoder.Decode(out)
-	)
+	err := unmarshalJSON(&c.unmarshaler, r.Body, out)
 	r.Body.Close()
 
-	return err
+	// requests without a body, e.g. GET ones, leave the message empty
+	if err != nil && err != io.EOF {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// generated handlers bind query parameters of pattern routes themselves
+	if _, ok := runtime.RouteFromContext(r.Context()); ok {
+		return nil
+	}
+	if msg, ok := out.(proto.Message); ok {
+		if err := runtime.PopulateQueryParameters(msg, r.URL.Query(), nil); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	return nil
 }
 
 func (c *RESTCodec) WriteResponse(w http.ResponseWriter, resp interface{}) error {
-	bResp, err := json.Marshal(resp)
+	bResp, err := marshalJSON(&c.marshaler, resp)
 	if err != nil {
 		return err
 	}
@@ -36,13 +97,98 @@ func (c *RESTCodec) WriteResponse(w http.ResponseWriter, resp interface{}) error
 	return err
 }
 
+// WriteError writes the error as {"error": "...", "code": ..., "message": "...", "details": [...]}
+// with the HTTP status returned by the error classifier, HTTPStatusFromError if it is nil.
+// The code, the message and the details are those of the gRPC status of the error.
 func (c *RESTCodec) WriteError(w http.ResponseWriter, err error) error {
-	bResp, _ := json.Marshal(&defaultError{Error: err.Error()})
+	code := HTTPStatusFromError(err)
+	if c.errorClassifier != nil {
+		code = c.errorClassifier(err)
+	}
+
+	bResp, _ := json.Marshal(&defaultError{
+		Error:       err.Error(),
+		errorStatus: *newErrorStatus(err),
+	})
+	w.WriteHeader(code)
 	_, err = w.Write(bResp)
 
 	return err
 }
 
+// HTTPStatusFromError returns the HTTP status corresponding to the gRPC status code of the error,
+// 500 for errors without one.
+func HTTPStatusFromError(err error) int {
+	return HTTPStatusFromCode(status.Code(err))
+}
+
+// HTTPStatusFromCode returns the HTTP status corresponding to a gRPC status code.
+// See https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto.
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// the de facto standard status of requests canceled by clients
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		// Unknown, Internal, DataLoss
+		return http.StatusInternalServerError
+	}
+}
+
+// CodeFromHTTPStatus returns the gRPC status code an HTTP status most likely corresponds to.
+// It is the inverse of HTTPStatusFromCode, where several codes share a status the most common is chosen.
+func CodeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case 499:
+		return codes.Canceled
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// defaultError is the body of REST errors: the error message, as older clients expect it,
+// along with the gRPC status of the error.
 type defaultError struct {
 	Error string `json:"error"`
+	errorStatus
 }
@@ -0,0 +1,101 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RESTClientCodec is the client-side counterpart of RESTCodec.
+// It posts the request message in the proto3 JSON mapping to the route.
+// Unknown fields of responses are ignored.
+type RESTClientCodec struct {
+	marshaler   jsonpb.Marshaler
+	unmarshaler jsonpb.Unmarshaler
+}
+
+func NewRESTClientCodec() ClientCodec {
+	return &RESTClientCodec{
+		unmarshaler: jsonpb.Unmarshaler{AllowUnknownFields: true},
+	}
+}
+
+func (c *RESTClientCodec) NewRequest(ctx context.Context, baseURL, route string, in interface{}) (*http.Request, error) {
+	body, err := marshalJSON(&c.marshaler, in)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(baseURL, "/")+route, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req.WithContext(ctx), nil
+}
+
+func (c *RESTClientCodec) ReadResponse(resp *http.Response, out interface{}) error {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !ok || isStreamed(resp) && isErrorBody(body) {
+		code := CodeFromHTTPStatus(resp.StatusCode)
+		if ok {
+			// the status of a stream is sent before its error
+			code = codes.Unknown
+		}
+
+		var e defaultError
+		if json.Unmarshal(body, &e) != nil || e.Error == "" {
+			return status.Error(code, fmt.Sprintf("unexpected HTTP status %s", resp.Status))
+		}
+		if e.Code == codes.OK {
+			return status.Error(code, e.Error)
+		}
+		return e.err()
+	}
+
+	if err := unmarshalJSON(&c.unmarshaler, bytes.NewReader(body), out); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+// isStreamed reports if the response is a line of a newline-delimited JSON stream,
+// which is an error if the stream fails once it has started.
+func isStreamed(resp *http.Response) bool {
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return contentType == runtime.ContentTypeNDJSON
+}
+
+// isErrorBody reports if the body is an error written by RESTCodec: an object with the "error" member,
+// and the members of the gRPC status unless it is written by an older server.
+func isErrorBody(body []byte) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil || fields["error"] == nil {
+		return false
+	}
+	for k := range fields {
+		switch k {
+		case "error", "code", "message", "details":
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package codec
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/struct"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRESTClientCodecReadResponse(t *testing.T) {
+	errorBody := `{"error": "no such person", "code": 5, "message": "no such person", "details": []}`
+
+	for _, tc := range []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		// code is OK if the body is decoded as the message
+		code codes.Code
+	}{
+		{
+			name:   "message",
+			status: http.StatusOK,
+			body:   `{"name": "bob"}`,
+		},
+		{
+			name:   "message looking like an error",
+			status: http.StatusOK,
+			body:   `{"error": "none", "code": 0}`,
+		},
+		{
+			name:   "error",
+			status: http.StatusNotFound,
+			body:   errorBody,
+			code:   codes.NotFound,
+		},
+		{
+			name:   "error of an older server",
+			status: http.StatusForbidden,
+			body:   `{"error": "denied"}`,
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "error which is not JSON",
+			status: http.StatusBadGateway,
+			body:   "bad gateway",
+			code:   codes.Unknown,
+		},
+		{
+			name:        "error in a stream",
+			status:      http.StatusOK,
+			contentType: runtime.ContentTypeNDJSON,
+			body:        errorBody,
+			code:        codes.NotFound,
+		},
+		{
+			name:        "error in a stream of an older server",
+			status:      http.StatusOK,
+			contentType: runtime.ContentTypeNDJSON,
+			body:        `{"error": "failure"}`,
+			code:        codes.Unknown,
+		},
+		{
+			name:        "message in a stream",
+			status:      http.StatusOK,
+			contentType: runtime.ContentTypeNDJSON,
+			body:        `{"name": "bob"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tc.status,
+				Status:     http.StatusText(tc.status),
+				Header:     http.Header{"Content-Type": {tc.contentType}},
+				Body:       ioutil.NopCloser(strings.NewReader(tc.body)),
+			}
+
+			out := &structpb.Struct{}
+			err := NewRESTClientCodec().ReadResponse(resp, out)
+			if tc.code != codes.OK {
+				if status.Code(err) != tc.code {
+					t.Fatalf("want a %s error, got %v", tc.code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := &structpb.Struct{}
+			if err := jsonpb.UnmarshalString(tc.body, want); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(out, want) {
+				t.Fatalf("want the body decoded as the message, got %v", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package codec
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	for _, tc := range []struct {
+		code   codes.Code
+		status int
+		// inverse is false if CodeFromHTTPStatus maps the status to another code
+		inverse bool
+	}{
+		{codes.OK, http.StatusOK, true},
+		{codes.Canceled, 499, true},
+		{codes.Unknown, http.StatusInternalServerError, true},
+		{codes.InvalidArgument, http.StatusBadRequest, true},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout, true},
+		{codes.NotFound, http.StatusNotFound, true},
+		{codes.AlreadyExists, http.StatusConflict, true},
+		{codes.PermissionDenied, http.StatusForbidden, true},
+		{codes.ResourceExhausted, http.StatusTooManyRequests, true},
+		{codes.FailedPrecondition, http.StatusBadRequest, false},
+		{codes.Aborted, http.StatusConflict, false},
+		{codes.OutOfRange, http.StatusBadRequest, false},
+		{codes.Unimplemented, http.StatusNotImplemented, true},
+		{codes.Internal, http.StatusInternalServerError, false},
+		{codes.Unavailable, http.StatusServiceUnavailable, true},
+		{codes.DataLoss, http.StatusInternalServerError, false},
+		{codes.Unauthenticated, http.StatusUnauthorized, true},
+	} {
+		t.Run(tc.code.String(), func(t *testing.T) {
+			if got := HTTPStatusFromCode(tc.code); got != tc.status {
+				t.Fatalf("want status %d, got %d", tc.status, got)
+			}
+			if got := CodeFromHTTPStatus(tc.status); tc.inverse && got != tc.code {
+				t.Fatalf("want CodeFromHTTPStatus(%d) to be %s, got %s", tc.status, tc.code, got)
+			}
+		})
+	}
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		code   codes.Code
+	}{
+		{http.StatusBadRequest, codes.InvalidArgument},
+		{http.StatusConflict, codes.AlreadyExists},
+		{http.StatusInternalServerError, codes.Unknown},
+		{http.StatusBadGateway, codes.Unknown},
+		{http.StatusMethodNotAllowed, codes.Unknown},
+		{http.StatusCreated, codes.Unknown},
+	} {
+		if got := CodeFromHTTPStatus(tc.status); got != tc.code {
+			t.Errorf("%d: want %s, got %s", tc.status, tc.code, got)
+		}
+	}
+}
+
+func TestRESTCodecWriteErrorStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		opts   []restOption
+		err    error
+		status int
+	}{
+		{
+			name:   "default classifier",
+			err:    status.Error(codes.NotFound, "no such person"),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "error without a status",
+			err:    errors.New("failure"),
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "nil classifier",
+			opts:   []restOption{WithRESTErrorClassifier(nil)},
+			err:    status.Error(codes.PermissionDenied, "denied"),
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "custom classifier",
+			opts:   []restOption{WithRESTErrorClassifier(func(error) int { return http.StatusTeapot })},
+			err:    status.Error(codes.NotFound, "no such person"),
+			status: http.StatusTeapot,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := NewRESTCCodec(tc.opts...).WriteError(rec, tc.err); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tc.status {
+				t.Fatalf("want status %d, got %d", tc.status, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package codec
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes/any"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	// the standard error details can be decoded by clients
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// errorStatus is a google.rpc.Status in the proto3 JSON mapping: every detail is an Any
+// object with the "@type" member along with the fields of the detail message.
+type errorStatus struct {
+	Code    codes.Code        `json:"code"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details"`
+}
+
+// newErrorStatus returns the gRPC status of err, Unknown for errors without one.
+// Details of message types which are not linked into the program are left out.
+func newErrorStatus(err error) *errorStatus {
+	st := status.Convert(err).Proto()
+	out := &errorStatus{
+		Code:    codes.Code(st.GetCode()),
+		Message: st.GetMessage(),
+		Details: []json.RawMessage{},
+	}
+
+	m := jsonpb.Marshaler{}
+	for _, detail := range st.GetDetails() {
+		s, err := m.MarshalToString(detail)
+		if err != nil {
+			continue
+		}
+		out.Details = append(out.Details, json.RawMessage(s))
+	}
+
+	return out
+}
+
+// err returns the error with the status. Details of unknown message types are left out.
+func (s *errorStatus) err() error {
+	st := &spb.Status{
+		Code:    int32(s.Code),
+		Message: s.Message,
+	}
+
+	u := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	for _, detail := range s.Details {
+		a := &any.Any{}
+		if err := u.Unmarshal(strings.NewReader(string(detail)), a); err != nil {
+			continue
+		}
+		st.Details = append(st.Details, a)
+	}
+
+	return status.ErrorProto(st)
+}
@@ -0,0 +1,73 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorStatusRoundTrip(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "invalid query").WithDetails(
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "ageFrom", Description: "must not be negative"},
+			{Field: "name", Description: "must not be empty"},
+		}},
+		&errdetails.RequestInfo{RequestId: "42"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(newErrorStatus(st.Err()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errorStatus
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	got := status.Convert(decoded.err())
+	if !proto.Equal(got.Proto(), st.Proto()) {
+		t.Fatalf("want %v, got %v from %s", st.Proto(), got.Proto(), b)
+	}
+	if len(got.Details()) != 2 {
+		t.Fatalf("want the details to be decoded, got %v", got.Details())
+	}
+	if _, ok := got.Details()[0].(*errdetails.BadRequest); !ok {
+		t.Fatalf("want a BadRequest detail, got %T", got.Details()[0])
+	}
+}
+
+func TestErrorStatusUnknownDetails(t *testing.T) {
+	st, err := status.New(codes.Internal, "failure").WithDetails(&errdetails.DebugInfo{Detail: "stack"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a detail of a message type which is not linked into the program
+	p := st.Proto()
+	p.Details = append(p.Details, &any.Any{TypeUrl: "type.googleapis.com/unknown.Detail", Value: []byte{}})
+
+	s := newErrorStatus(status.ErrorProto(p))
+	if len(s.Details) != 1 {
+		t.Fatalf("want the unknown detail to be left out, got %s", s.Details)
+	}
+
+	s.Details = append(s.Details, json.RawMessage(`{"@type": "type.googleapis.com/unknown.Detail", "field": 1}`))
+	if got := status.Convert(s.err()); got.Code() != codes.Internal || len(got.Details()) != 1 {
+		t.Fatalf("want the unknown detail to be left out, got %v", got.Proto())
+	}
+}
+
+func TestErrorStatusWithoutStatus(t *testing.T) {
+	s := newErrorStatus(errors.New("failure"))
+	if s.Code != codes.Unknown || s.Message != "failure" || len(s.Details) != 0 {
+		t.Fatalf("want an Unknown status, got %+v", s)
+	}
+}
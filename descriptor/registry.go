@@ -10,6 +10,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	gendesc "github.com/golang/protobuf/protoc-gen-go/descriptor"
 	"github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/lazada/protoc-gen-go-http/runtime"
 	options "google.golang.org/genproto/googleapis/api/annotations"
 )
 
@@ -32,6 +33,9 @@ type Registry struct {
 
 	// pkgAliases is a mapping from package aliases to package paths in go which are already taken.
 	pkgAliases map[string]string
+
+	// allowDeleteBody permits http delete methods to have a body
+	allowDeleteBody bool
 }
 
 // NewRegistry returns a new Registry.
@@ -211,6 +215,12 @@ func (r *Registry) SetPrefix(prefix string) {
 	r.prefix = prefix
 }
 
+// SetAllowDeleteBody controls whether http delete methods may have a
+// body or fail loading if encountered.
+func (r *Registry) SetAllowDeleteBody(allow bool) {
+	r.allowDeleteBody = allow
+}
+
 // ReserveGoPackageAlias reserves the unique alias of go package.
 // If succeeded, the alias will be never used for other packages in generated go files.
 // If failed, the alias is already taken by another package, so you need to use another
@@ -319,9 +329,203 @@ func (r *Registry) newMethod(svc *Service, md *gendesc.MethodDescriptorProto, op
 		ResponseType:          responseType,
 	}
 
+	newBinding := func(opts *options.HttpRule, idx int) (*Binding, error) {
+		var (
+			httpMethod   string
+			pathTemplate string
+		)
+		switch {
+		case opts.GetGet() != "":
+			httpMethod = "GET"
+			pathTemplate = opts.GetGet()
+			if opts.GetBody() != "" {
+				return nil, fmt.Errorf("must not set request body when http method is GET: %s", md.GetName())
+			}
+		case opts.GetPut() != "":
+			httpMethod = "PUT"
+			pathTemplate = opts.GetPut()
+		case opts.GetPost() != "":
+			httpMethod = "POST"
+			pathTemplate = opts.GetPost()
+		case opts.GetDelete() != "":
+			httpMethod = "DELETE"
+			pathTemplate = opts.GetDelete()
+			if opts.GetBody() != "" && !r.allowDeleteBody {
+				return nil, fmt.Errorf("must not set request body when http method is DELETE except allow_delete_body option is true: %s", md.GetName())
+			}
+		case opts.GetPatch() != "":
+			httpMethod = "PATCH"
+			pathTemplate = opts.GetPatch()
+		case opts.GetCustom() != nil:
+			custom := opts.GetCustom()
+			httpMethod = custom.GetKind()
+			pathTemplate = custom.GetPath()
+		default:
+			glog.V(1).Infof("No pattern specified in google.api.HttpRule: %s", md.GetName())
+			return nil, nil
+		}
+
+		if httpMethod == "" || strings.ContainsAny(httpMethod, " \t") {
+			return nil, fmt.Errorf("invalid HTTP method %q in %s.%s", httpMethod, svc.GetName(), md.GetName())
+		}
+		tmpl, err := runtime.ParsePattern(httpMethod + " " + pathTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", svc.GetName(), md.GetName(), err)
+		}
+
+		if md.GetClientStreaming() && len(tmpl.Fields()) > 0 {
+			return nil, fmt.Errorf("cannot use path parameter in client streaming: %s.%s", svc.GetName(), md.GetName())
+		}
+		if md.GetClientStreaming() && opts.GetBody() != "" && opts.GetBody() != "*" {
+			return nil, fmt.Errorf("body of client streaming %s.%s must be \"*\"", svc.GetName(), md.GetName())
+		}
+		if (md.GetClientStreaming() || md.GetServerStreaming()) && opts.GetResponseBody() != "" {
+			return nil, fmt.Errorf("cannot use response_body in streaming: %s.%s", svc.GetName(), md.GetName())
+		}
+
+		b := &Binding{
+			Method:     meth,
+			Index:      idx,
+			HTTPMethod: httpMethod,
+			PathTmpl:   tmpl,
+		}
+		for _, f := range tmpl.Fields() {
+			param, err := r.newParam(meth, f)
+			if err != nil {
+				return nil, err
+			}
+			b.PathParams = append(b.PathParams, param)
+		}
+
+		if b.Body, err = r.newBody(meth.RequestType, opts.GetBody()); err != nil {
+			return nil, fmt.Errorf("body of %s.%s: %v", svc.GetName(), md.GetName(), err)
+		}
+		if b.ResponseBody, err = r.newBody(meth.ResponseType, opts.GetResponseBody()); err != nil {
+			return nil, fmt.Errorf("response_body of %s.%s: %v", svc.GetName(), md.GetName(), err)
+		}
+		if b.ResponseBody != nil && len(b.ResponseBody.FieldPath) == 0 {
+			return nil, fmt.Errorf("response_body of %s.%s must be a field name", svc.GetName(), md.GetName())
+		}
+
+		return b, nil
+	}
+
+	if opts != nil {
+		b, err := newBinding(opts, 0)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			meth.Bindings = append(meth.Bindings, b)
+		}
+
+		for i, additional := range opts.GetAdditionalBindings() {
+			if len(additional.GetAdditionalBindings()) > 0 {
+				return nil, fmt.Errorf("additional_binding in additional_binding not allowed: %s.%s", svc.GetName(), meth.GetName())
+			}
+			b, err := newBinding(additional, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if b != nil {
+				meth.Bindings = append(meth.Bindings, b)
+			}
+		}
+	}
+
 	return meth, nil
 }
 
+func (r *Registry) newParam(meth *Method, path string) (Parameter, error) {
+	msg := meth.RequestType
+	fields, err := r.resolveFieldPath(msg, path)
+	if err != nil {
+		return Parameter{}, err
+	}
+	l := len(fields)
+	if l == 0 {
+		return Parameter{}, fmt.Errorf("invalid field access list for %s", path)
+	}
+	target := fields[l-1].Target
+	switch target.GetType() {
+	case gendesc.FieldDescriptorProto_TYPE_MESSAGE, gendesc.FieldDescriptorProto_TYPE_GROUP:
+		if !IsWellKnownType(target.GetTypeName()) {
+			return Parameter{}, fmt.Errorf("aggregate type %s in parameter of %s.%s: %s", target.GetType(), meth.Service.GetName(), meth.GetName(), path)
+		}
+		glog.V(2).Infoln("found well known aggregate type:", target)
+	}
+	return Parameter{
+		FieldPath: FieldPath(fields),
+		Method:    meth,
+		Target:    target,
+	}, nil
+}
+
+// newBody resolves a body selector of google.api.HttpRule against msg.
+// It returns nil for an omitted body and a Body with an empty FieldPath for "*".
+func (r *Registry) newBody(msg *Message, path string) (*Body, error) {
+	switch path {
+	case "":
+		return nil, nil
+	case "*":
+		return &Body{FieldPath: nil}, nil
+	}
+
+	// the field must be present at the top-level of the message
+	f := lookupField(msg, path)
+	if f == nil {
+		return nil, fmt.Errorf("no field %q found in %s", path, msg.GetName())
+	}
+	return &Body{FieldPath: FieldPath{{Name: path, Target: f}}}, nil
+}
+
+// lookupField looks up a field named "name" within "msg".
+// It returns nil if no such field found.
+func lookupField(msg *Message, name string) *Field {
+	for _, f := range msg.Fields {
+		if f.GetName() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// resolveFieldPath resolves "path" into a list of fieldDescriptor, starting from "msg".
+func (r *Registry) resolveFieldPath(msg *Message, path string) ([]FieldPathComponent, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	root := msg
+	var result []FieldPathComponent
+	for i, c := range strings.Split(path, ".") {
+		if i > 0 {
+			f := result[i-1].Target
+			switch f.GetType() {
+			case gendesc.FieldDescriptorProto_TYPE_MESSAGE, gendesc.FieldDescriptorProto_TYPE_GROUP:
+				var err error
+				msg, err = r.LookupMsg(msg.FQMN(), f.GetTypeName())
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("not an aggregate type: %s in %s", f.GetName(), path)
+			}
+		}
+
+		glog.V(2).Infof("Lookup %s in %s", c, msg.FQMN())
+		f := lookupField(msg, c)
+		if f == nil {
+			return nil, fmt.Errorf("no field %q found in %s", path, root.GetName())
+		}
+		if f.GetLabel() == gendesc.FieldDescriptorProto_LABEL_REPEATED {
+			return nil, fmt.Errorf("repeated field not allowed in field path: %s in %s", f.GetName(), path)
+		}
+		result = append(result, FieldPathComponent{Name: c, Target: f})
+	}
+	return result, nil
+}
+
 func extractAPIOptions(meth *gendesc.MethodDescriptorProto) (*options.HttpRule, error) {
 	if meth.Options == nil {
 		return nil, nil
@@ -6,6 +6,7 @@ import (
 
 	gendesc "github.com/golang/protobuf/protoc-gen-go/descriptor"
 	gogen "github.com/golang/protobuf/protoc-gen-go/generator"
+	"github.com/lazada/protoc-gen-go-http/runtime"
 )
 
 // GoPackage represents a golang package
@@ -133,6 +134,64 @@ type Method struct {
 	RequestType *Message
 	// ResponseType is the message type of responses from this method.
 	ResponseType *Message
+	// Bindings are the HTTP endpoint bindings of this method.
+	Bindings []*Binding
+}
+
+// Binding describes how an HTTP endpoint is bound to a gRPC method.
+type Binding struct {
+	// Method is the method which the endpoint is bound to.
+	Method *Method
+	// Index is a zero-origin index of the binding in the target method.
+	Index int
+	// HTTPMethod is the HTTP method which this method is mapped to.
+	HTTPMethod string
+	// PathTmpl is the path template where this method is mapped to.
+	PathTmpl runtime.Pattern
+	// PathParams is the list of parameters provided in HTTP request paths.
+	PathParams []Parameter
+	// Body describes parameters provided in HTTP request body.
+	// It is nil if the request body is not mapped to the request message.
+	Body *Body
+	// ResponseBody describes the field of the response message written as HTTP response body.
+	// It is nil if the whole response message is written.
+	ResponseBody *Body
+}
+
+// Body describes a http (request|response) body to be sent to the (method|client).
+// This is used in body and response_body options in google.api.HttpRule
+type Body struct {
+	// FieldPath is a path to a proto field which the (request|response) body is mapped to.
+	// The (request|response) body is mapped to the (request|response) type itself if FieldPath is empty.
+	FieldPath FieldPath
+}
+
+// Route returns the pattern route of the binding, e.g. "GET /v1/{name=books/*}".
+func (b *Binding) Route() string {
+	return b.PathTmpl.String()
+}
+
+// Parameter is a parameter provided in http requests
+type Parameter struct {
+	// FieldPath is a path to a proto field which this parameter is mapped to.
+	FieldPath
+	// Target is the proto field which this parameter is mapped to.
+	Target *Field
+	// Method is the method which this parameter is used for.
+	Method *Method
+}
+
+// ConvertFuncExpr returns a go expression of a converter function.
+// The converter function converts a string into a value for the parameter.
+// It returns false if the field type has no converter function, which means
+// the parameter has to be populated by runtime.PopulateFieldFromPath.
+func (p Parameter) ConvertFuncExpr() (string, bool) {
+	tbl := proto3ConvertFuncs
+	if p.Target.Message.File.proto2() {
+		tbl = proto2ConvertFuncs
+	}
+	conv, ok := tbl[p.Target.GetType()]
+	return conv, ok
 }
 
 // Field wraps gendesc.FieldDescriptorProto for richer features.
@@ -250,4 +309,25 @@ var (
 		gendesc.FieldDescriptorProto_TYPE_SINT32:   "runtime.Int32P",
 		gendesc.FieldDescriptorProto_TYPE_SINT64:   "runtime.Int64P",
 	}
+
+	// wellKnownTypes are the well-known types which can be populated from a single string.
+	wellKnownTypes = map[string]bool{
+		".google.protobuf.Timestamp":   true,
+		".google.protobuf.Duration":    true,
+		".google.protobuf.StringValue": true,
+		".google.protobuf.FloatValue":  true,
+		".google.protobuf.DoubleValue": true,
+		".google.protobuf.BoolValue":   true,
+		".google.protobuf.BytesValue":  true,
+		".google.protobuf.Int32Value":  true,
+		".google.protobuf.UInt32Value": true,
+		".google.protobuf.Int64Value":  true,
+		".google.protobuf.UInt64Value": true,
+	}
 )
+
+// IsWellKnownType returns true if the provided fully qualified type name is
+// a well-known type which can be populated from a single string.
+func IsWellKnownType(typeName string) bool {
+	return wellKnownTypes[typeName]
+}
@@ -0,0 +1,68 @@
+package bindings
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/lazada/protoc-gen-go-http/codec"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = codes.OK
+var _ = status.Errorf
+
+type httpLibraryClient struct {
+	baseURL string
+	client  *http.Client
+	cdc     codec.ClientCodec
+}
+
+// NewLibraryHTTPClient returns LibraryClient calling the LibraryRouter served at baseURL.
+// Call options are ignored. Server-streaming methods ask for newline-delimited JSON,
+// client-streaming and bidirectional streaming ones are not supported over HTTP.
+func NewLibraryHTTPClient(baseURL string, httpClient *http.Client, cdc codec.ClientCodec) LibraryClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &httpLibraryClient{
+		baseURL: baseURL,
+		client:  httpClient,
+		cdc:     cdc,
+	}
+}
+
+func (c *httpLibraryClient) GetBook(ctx context.Context, in *GetBookRequest, opts ...grpc.CallOption) (*Book, error) {
+	out := new(Book)
+	if err := codec.Invoke(ctx, c.client, c.cdc, c.baseURL, "/library/getbook", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *httpLibraryClient) CreateBook(ctx context.Context, in *CreateBookRequest, opts ...grpc.CallOption) (*Book, error) {
+	out := new(Book)
+	if err := codec.Invoke(ctx, c.client, c.cdc, c.baseURL, "/library/createbook", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *httpLibraryClient) TagBook(ctx context.Context, in *TagBookRequest, opts ...grpc.CallOption) (*Book, error) {
+	out := new(Book)
+	if err := codec.Invoke(ctx, c.client, c.cdc, c.baseURL, "/library/tagbook", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *httpLibraryClient) ListBooks(ctx context.Context, in *ListBooksRequest, opts ...grpc.CallOption) (*ListBooksResponse, error) {
+	out := new(ListBooksResponse)
+	if err := codec.Invoke(ctx, c.client, c.cdc, c.baseURL, "/library/listbooks", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,83 @@
+package bindings
+
+import (
+	"net/http"
+
+	"github.com/lazada/protoc-gen-go-http/codec"
+)
+
+type HTTPLibraryServer struct {
+	srv LibraryServer
+	cdc codec.Codec
+}
+
+func NewHTTPLibraryServer(srv LibraryServer, cdc codec.Codec) *HTTPLibraryServer {
+	return &HTTPLibraryServer{
+		srv: srv,
+		cdc: cdc,
+	}
+}
+
+func (s *HTTPLibraryServer) GetBook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	arg := GetBookRequest{}
+	err := s.cdc.ReadRequest(r, &arg)
+	if err != nil {
+		s.cdc.WriteError(w, err)
+		return
+	}
+	grpcResp, err := s.srv.GetBook(r.Context(), &arg)
+	if err != nil {
+		s.cdc.WriteError(w, err)
+		return
+	}
+	s.cdc.WriteResponse(w, grpcResp)
+}
+
+func (s *HTTPLibraryServer) CreateBook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	arg := CreateBookRequest{}
+	err := s.cdc.ReadRequest(r, &arg)
+	if err != nil {
+		s.cdc.WriteError(w, err)
+		return
+	}
+	grpcResp, err := s.srv.CreateBook(r.Context(), &arg)
+	if err != nil {
+		s.cdc.WriteError(w, err)
+		return
+	}
+	s.cdc.WriteResponse(w, grpcResp)
+}
+
+func (s *HTTPLibraryServer) TagBook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	arg := TagBookRequest{}
+	err := s.cdc.ReadRequest(r, &arg)
+	if err != nil {
+		s.cdc.WriteError(w, err)
+		return
+	}
+	grpcResp, err := s.srv.TagBook(r.Context(), &arg)
+	if err != nil {
+		s.cdc.WriteError(w, err)
+		return
+	}
+	s.cdc.WriteResponse(w, grpcResp)
+}
+
+func (s *HTTPLibraryServer) ListBooks(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	arg := ListBooksRequest{}
+	err := s.cdc.ReadRequest(r, &arg)
+	if err != nil {
+		s.cdc.WriteError(w, err)
+		return
+	}
+	grpcResp, err := s.srv.ListBooks(r.Context(), &arg)
+	if err != nil {
+		s.cdc.WriteError(w, err)
+		return
+	}
+	s.cdc.WriteResponse(w, grpcResp)
+}
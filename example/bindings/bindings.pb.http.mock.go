@@ -0,0 +1,218 @@
+package bindings
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = io.EOF
+var _ context.Context
+
+// LibraryMockCall is a call received by LibraryMock.
+type LibraryMockCall struct {
+	// Method is the name of the called method as in the service definition.
+	Method string
+	// Metadata is the incoming metadata of the call.
+	Metadata metadata.MD
+	// Requests are the request messages received so far, a single one for unary
+	// and server-streaming methods.
+	Requests []proto.Message
+}
+
+// LibraryMock is a LibraryServer answering with canned or scripted responses,
+// which records the calls it receives. Methods without responses return an Unimplemented error.
+// Serve it with NewLibraryRouter to test consumers against the real codec and routes.
+type LibraryMock struct {
+	// state is a field of its own, so that it cannot collide with the methods of the service
+	state struct {
+		mu    sync.Mutex
+		calls []*LibraryMockCall
+
+		onGetBook    func(ctx context.Context, in *GetBookRequest) (*Book, error)
+		onCreateBook func(ctx context.Context, in *CreateBookRequest) (*Book, error)
+		onTagBook    func(ctx context.Context, in *TagBookRequest) (*Book, error)
+		onListBooks  func(ctx context.Context, in *ListBooksRequest) (*ListBooksResponse, error)
+	}
+}
+
+// NewLibraryMock returns a LibraryMock without responses.
+func NewLibraryMock() *LibraryMock {
+	return &LibraryMock{}
+}
+
+// MockCalls returns the calls received so far, in order.
+func (m *LibraryMock) MockCalls() []LibraryMockCall {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	calls := make([]LibraryMockCall, len(m.state.calls))
+	for i, call := range m.state.calls {
+		calls[i] = *call
+		calls[i].Requests = append([]proto.Message(nil), call.Requests...)
+	}
+	return calls
+}
+
+// MockCallsTo returns the calls of the method received so far, in order.
+func (m *LibraryMock) MockCallsTo(method string) []LibraryMockCall {
+	var calls []LibraryMockCall
+	for _, call := range m.MockCalls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// MockReset forgets the calls received so far. Responses are kept.
+func (m *LibraryMock) MockReset() {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.calls = nil
+}
+
+func (m *LibraryMock) record(ctx context.Context, method string, reqs ...proto.Message) *LibraryMockCall {
+	md, _ := metadata.FromIncomingContext(ctx)
+	call := &LibraryMockCall{
+		Method:   method,
+		Metadata: md,
+		Requests: reqs,
+	}
+
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.calls = append(m.state.calls, call)
+	return call
+}
+
+func (m *LibraryMock) recordRequest(call *LibraryMockCall, req proto.Message) {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	call.Requests = append(call.Requests, req)
+}
+
+// OnGetBook scripts GetBook with f, which serves every call after it is recorded.
+func (m *LibraryMock) OnGetBook(f func(ctx context.Context, in *GetBookRequest) (*Book, error)) *LibraryMock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.onGetBook = f
+	return m
+}
+
+// ReturnGetBook makes GetBook answer every call with resp and err.
+func (m *LibraryMock) ReturnGetBook(resp *Book, err error) *LibraryMock {
+	return m.OnGetBook(func(context.Context, *GetBookRequest) (*Book, error) {
+		return resp, err
+	})
+}
+
+func (m *LibraryMock) GetBook(ctx context.Context, in *GetBookRequest) (*Book, error) {
+	m.record(ctx, "GetBook", in)
+
+	m.state.mu.Lock()
+	f := m.state.onGetBook
+	m.state.mu.Unlock()
+	if f == nil {
+		return nil, status.Errorf(codes.Unimplemented, "method GetBook is not mocked")
+	}
+
+	return f(ctx, in)
+}
+
+// OnCreateBook scripts CreateBook with f, which serves every call after it is recorded.
+func (m *LibraryMock) OnCreateBook(f func(ctx context.Context, in *CreateBookRequest) (*Book, error)) *LibraryMock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.onCreateBook = f
+	return m
+}
+
+// ReturnCreateBook makes CreateBook answer every call with resp and err.
+func (m *LibraryMock) ReturnCreateBook(resp *Book, err error) *LibraryMock {
+	return m.OnCreateBook(func(context.Context, *CreateBookRequest) (*Book, error) {
+		return resp, err
+	})
+}
+
+func (m *LibraryMock) CreateBook(ctx context.Context, in *CreateBookRequest) (*Book, error) {
+	m.record(ctx, "CreateBook", in)
+
+	m.state.mu.Lock()
+	f := m.state.onCreateBook
+	m.state.mu.Unlock()
+	if f == nil {
+		return nil, status.Errorf(codes.Unimplemented, "method CreateBook is not mocked")
+	}
+
+	return f(ctx, in)
+}
+
+// OnTagBook scripts TagBook with f, which serves every call after it is recorded.
+func (m *LibraryMock) OnTagBook(f func(ctx context.Context, in *TagBookRequest) (*Book, error)) *LibraryMock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.onTagBook = f
+	return m
+}
+
+// ReturnTagBook makes TagBook answer every call with resp and err.
+func (m *LibraryMock) ReturnTagBook(resp *Book, err error) *LibraryMock {
+	return m.OnTagBook(func(context.Context, *TagBookRequest) (*Book, error) {
+		return resp, err
+	})
+}
+
+func (m *LibraryMock) TagBook(ctx context.Context, in *TagBookRequest) (*Book, error) {
+	m.record(ctx, "TagBook", in)
+
+	m.state.mu.Lock()
+	f := m.state.onTagBook
+	m.state.mu.Unlock()
+	if f == nil {
+		return nil, status.Errorf(codes.Unimplemented, "method TagBook is not mocked")
+	}
+
+	return f(ctx, in)
+}
+
+// OnListBooks scripts ListBooks with f, which serves every call after it is recorded.
+func (m *LibraryMock) OnListBooks(f func(ctx context.Context, in *ListBooksRequest) (*ListBooksResponse, error)) *LibraryMock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.onListBooks = f
+	return m
+}
+
+// ReturnListBooks makes ListBooks answer every call with resp and err.
+func (m *LibraryMock) ReturnListBooks(resp *ListBooksResponse, err error) *LibraryMock {
+	return m.OnListBooks(func(context.Context, *ListBooksRequest) (*ListBooksResponse, error) {
+		return resp, err
+	})
+}
+
+func (m *LibraryMock) ListBooks(ctx context.Context, in *ListBooksRequest) (*ListBooksResponse, error) {
+	m.record(ctx, "ListBooks", in)
+
+	m.state.mu.Lock()
+	f := m.state.onListBooks
+	m.state.mu.Unlock()
+	if f == nil {
+		return nil, status.Errorf(codes.Unimplemented, "method ListBooks is not mocked")
+	}
+
+	return f(ctx, in)
+}
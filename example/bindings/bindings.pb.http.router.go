@@ -0,0 +1,499 @@
+package bindings
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/lazada/protoc-gen-go-http/codec"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ grpc.ServerStream
+var _ = codes.OK
+var _ = status.Errorf
+
+type options struct {
+	routes           map[string]http.HandlerFunc
+	withSwagger      bool
+	headerMatcher    runtime.HeaderMatcher
+	batchConcurrency int
+	maxBatchLength   int
+	methodNaming     runtime.MethodNaming
+}
+
+func (o *options) validate() error {
+	if o.routes == nil {
+		return errors.New("nil routes were provided")
+	}
+
+	return nil
+}
+
+type option func(*options)
+
+// SwaggerPath is the path the Swagger document is served at with WithSwagger.
+const SwaggerPath = "/swagger.json"
+
+// swaggerJSON is the Swagger document of the services defined in the file.
+var swaggerJSON = []byte("{\"swagger\":\"2.0\",\"info\":{\"title\":\"bindings.proto\",\"version\":\"version not set\"},\"tags\":[{\"name\":\"Library\"}],\"consumes\":[\"application/json\"],\"produces\":[\"application/json\"],\"paths\":{\"/v1/books\":{\"put\":{\"summary\":\"A body field, or the whole body.\",\"operationId\":\"Library_CreateBook_1\",\"tags\":[\"Library\"],\"parameters\":[{\"name\":\"body\",\"in\":\"body\",\"required\":true,\"schema\":{\"$ref\":\"#/definitions/bindings.CreateBookRequest\"}}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"$ref\":\"#/definitions/bindings.Book\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}},\"/v1/books/{id}\":{\"get\":{\"summary\":\"Path variables and query parameters.\",\"operationId\":\"Library_GetBook_1\",\"tags\":[\"Library\"],\"parameters\":[{\"name\":\"id\",\"in\":\"path\",\"required\":true,\"type\":\"string\",\"format\":\"int64\"},{\"name\":\"name\",\"in\":\"query\",\"type\":\"string\"},{\"name\":\"view\",\"in\":\"query\",\"type\":\"string\"}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"$ref\":\"#/definitions/bindings.Book\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}},\"/v1/{name}\":{\"get\":{\"summary\":\"Path variables and query parameters.\",\"operationId\":\"Library_GetBook\",\"tags\":[\"Library\"],\"parameters\":[{\"name\":\"name\",\"in\":\"path\",\"required\":true,\"type\":\"string\"},{\"name\":\"id\",\"in\":\"query\",\"type\":\"string\",\"format\":\"int64\"},{\"name\":\"view\",\"in\":\"query\",\"type\":\"string\"}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"$ref\":\"#/definitions/bindings.Book\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}},\"/v1/{name}/tags\":{\"post\":{\"summary\":\"A repeated body field.\",\"operationId\":\"Library_TagBook\",\"tags\":[\"Library\"],\"parameters\":[{\"name\":\"name\",\"in\":\"path\",\"required\":true,\"type\":\"string\"},{\"name\":\"body\",\"in\":\"body\",\"required\":true,\"schema\":{\"type\":\"array\",\"items\":{\"type\":\"string\"}}},{\"name\":\"replace\",\"in\":\"query\",\"type\":\"boolean\"}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"$ref\":\"#/definitions/bindings.Book\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}},\"/v1/{parent}/books\":{\"get\":{\"summary\":\"Response fields, or the whole response.\",\"operationId\":\"Library_ListBooks\",\"tags\":[\"Library\"],\"parameters\":[{\"name\":\"parent\",\"in\":\"path\",\"required\":true,\"type\":\"string\"},{\"name\":\"page_size\",\"in\":\"query\",\"type\":\"integer\",\"format\":\"int32\"}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"type\":\"array\",\"items\":{\"$ref\":\"#/definitions/bindings.Book\"}}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}},\"post\":{\"summary\":\"A body field, or the whole body.\",\"operationId\":\"Library_CreateBook\",\"tags\":[\"Library\"],\"parameters\":[{\"name\":\"parent\",\"in\":\"path\",\"required\":true,\"type\":\"string\"},{\"name\":\"body\",\"in\":\"body\",\"required\":true,\"schema\":{\"$ref\":\"#/definitions/bindings.Book\"}},{\"name\":\"request_id\",\"in\":\"query\",\"type\":\"string\"}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"$ref\":\"#/definitions/bindings.Book\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}},\"/v1/{parent}/next\":{\"get\":{\"summary\":\"Response fields, or the whole response.\",\"operationId\":\"Library_ListBooks_1\",\"tags\":[\"Library\"],\"parameters\":[{\"name\":\"parent\",\"in\":\"path\",\"required\":true,\"type\":\"string\"},{\"name\":\"page_size\",\"in\":\"query\",\"type\":\"integer\",\"format\":\"int32\"}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"type\":\"string\",\"format\":\"int64\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}},\"/v2/{parent}/books\":{\"get\":{\"summary\":\"Response fields, or the whole response.\",\"operationId\":\"Library_ListBooks_2\",\"tags\":[\"Library\"],\"parameters\":[{\"name\":\"parent\",\"in\":\"path\",\"required\":true,\"type\":\"string\"},{\"name\":\"page_size\",\"in\":\"query\",\"type\":\"integer\",\"format\":\"int32\"}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"$ref\":\"#/definitions/bindings.ListBooksResponse\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}}},\"definitions\":{\"bindings.Book\":{\"type\":\"object\",\"properties\":{\"name\":{\"type\":\"string\"},\"pages\":{\"type\":\"string\",\"format\":\"int64\"},\"tags\":{\"type\":\"array\",\"items\":{\"type\":\"string\"}},\"title\":{\"type\":\"string\"}}},\"bindings.CreateBookRequest\":{\"type\":\"object\",\"properties\":{\"book\":{\"$ref\":\"#/definitions/bindings.Book\"},\"parent\":{\"type\":\"string\"},\"requestId\":{\"type\":\"string\"}}},\"bindings.ListBooksResponse\":{\"type\":\"object\",\"properties\":{\"books\":{\"type\":\"array\",\"items\":{\"$ref\":\"#/definitions/bindings.Book\"}},\"nextPageToken\":{\"type\":\"string\",\"format\":\"int64\"}}},\"bindings.TagBookRequest\":{\"type\":\"object\",\"properties\":{\"name\":{\"type\":\"string\"},\"replace\":{\"type\":\"boolean\"},\"tags\":{\"type\":\"array\",\"items\":{\"type\":\"string\"}}}},\"httpError\":{\"type\":\"object\",\"properties\":{\"code\":{\"description\":\"The gRPC status code.\",\"type\":\"integer\",\"format\":\"int32\"},\"details\":{\"description\":\"The error details as typed JSON Any objects.\",\"type\":\"array\",\"items\":{\"type\":\"object\",\"properties\":{\"@type\":{\"type\":\"string\"}}}},\"error\":{\"type\":\"string\"},\"message\":{\"type\":\"string\"}}}}}")
+
+// WithRoutes sets handlers to specific routes.
+// Routes of the "<HTTP method> <path template>" form, e.g. "GET /v1/{name=books/*}",
+// are matched against the request method and path.
+// Set the handler to nil to delete a route.
+func WithRoutes(routes map[string]http.HandlerFunc) option {
+	return func(opts *options) {
+		opts.routes = routes
+	}
+}
+
+// WithSwagger serves the Swagger document of the services at SwaggerPath.
+func WithSwagger() option {
+	return func(opts *options) {
+		opts.withSwagger = true
+	}
+}
+
+// WithHeaderMatcher sets which request headers are forwarded into the incoming gRPC metadata
+// of the methods, runtime.DefaultHeaderMatcher by default. A nil matcher forwards no headers.
+func WithHeaderMatcher(m runtime.HeaderMatcher) option {
+	return func(opts *options) {
+		opts.headerMatcher = m
+	}
+}
+
+// WithMethodNaming sets the naming scheme of the methods in JSON-RPC requests, runtime.PathNaming by default.
+// Methods can always be called by their routes, e.g. "/example/getperson", as well.
+func WithMethodNaming(naming runtime.MethodNaming) option {
+	return func(opts *options) {
+		opts.methodNaming = naming
+	}
+}
+
+// WithBatchConcurrency serves up to n calls of a batch request, e.g. a JSON-RPC batch,
+// concurrently. They are served one at a time by default.
+func WithBatchConcurrency(n int) option {
+	return func(opts *options) {
+		opts.batchConcurrency = n
+	}
+}
+
+// WithMaxBatchLength rejects batch requests of more than n calls, codec.DefaultMaxBatchLength by default.
+// Their length is not limited if n is less than 1.
+func WithMaxBatchLength(n int) option {
+	return func(opts *options) {
+		opts.maxBatchLength = n
+	}
+}
+
+type LibraryRouter struct {
+	srv              *httpLibraryServer
+	codecBuilder     codec.CodecBuilder
+	routes           map[string]http.HandlerFunc
+	streams          map[string]bool
+	matcher          *runtime.Matcher
+	headerMatcher    runtime.HeaderMatcher
+	batchConcurrency int
+	maxBatchLength   int
+	withSwagger      bool
+}
+
+func NewLibraryRouter(srv LibraryServer, codecBuilder codec.CodecBuilder, opts ...option) (*LibraryRouter, error) {
+	out := &LibraryRouter{
+		srv:          newHTTPLibraryServer(srv, codecBuilder),
+		codecBuilder: codecBuilder,
+		routes:       make(map[string]http.HandlerFunc),
+	}
+
+	defaultOptions := &options{
+		routes:         make(map[string]http.HandlerFunc),
+		headerMatcher:  runtime.DefaultHeaderMatcher,
+		maxBatchLength: codec.DefaultMaxBatchLength,
+		methodNaming:   runtime.PathNaming,
+	}
+
+	for _, opt := range opts {
+		opt(defaultOptions)
+	}
+
+	if err := defaultOptions.validate(); err != nil {
+		return nil, err
+	}
+	out.withSwagger = defaultOptions.withSwagger
+	out.headerMatcher = defaultOptions.headerMatcher
+	out.batchConcurrency = defaultOptions.batchConcurrency
+	out.maxBatchLength = defaultOptions.maxBatchLength
+
+	out.routes = map[string]http.HandlerFunc{
+		"/library/getbook":                       out.srv.GetBook,
+		"GET /v1/{name=shelves/*/books/*}":       out.srv.GetBook,
+		"GET /v1/books/{id}":                     out.srv.GetBook,
+		"/library/createbook":                    out.srv.CreateBook,
+		"POST /v1/{parent=shelves/*}/books":      out.srv.CreateBook,
+		"PUT /v1/books":                          out.srv.CreateBook,
+		"/library/tagbook":                       out.srv.TagBook,
+		"POST /v1/{name=shelves/*/books/*}/tags": out.srv.TagBook,
+		"/library/listbooks":                     out.srv.ListBooks,
+		"GET /v1/{parent=shelves/*}/books":       out.srv.ListBooks,
+		"GET /v1/{parent=shelves/*}/next":        out.srv.ListBooks,
+		"GET /v2/{parent=shelves/*}/books":       out.srv.ListBooks,
+	}
+
+	// the routes of streaming methods, which cannot be called in batches
+	out.streams = map[string]bool{}
+
+	if naming := defaultOptions.methodNaming; naming != nil {
+		out.routes[naming("/bindings.Library/GetBook")] = out.srv.GetBook
+		out.routes[naming("/bindings.Library/CreateBook")] = out.srv.CreateBook
+		out.routes[naming("/bindings.Library/TagBook")] = out.srv.TagBook
+		out.routes[naming("/bindings.Library/ListBooks")] = out.srv.ListBooks
+	}
+
+	for route, handler := range defaultOptions.routes {
+		if handler == nil {
+			continue
+		}
+		out.routes[route] = handler
+		delete(out.streams, route)
+	}
+
+	routes := make([]string, 0, len(out.routes))
+	for route := range out.routes {
+		routes = append(routes, route)
+	}
+
+	matcher, err := runtime.NewMatcher(routes)
+	if err != nil {
+		return nil, err
+	}
+	out.matcher = matcher
+
+	return out, nil
+}
+
+func (s *LibraryRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.withSwagger && swaggerJSON != nil && r.Method == http.MethodGet && r.URL.Path == SwaggerPath {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(swaggerJSON)
+		return
+	}
+
+	if codec.ServeBatch(w, r, s.codecBuilder, http.HandlerFunc(s.serve), s.batchConcurrency, s.maxBatchLength) {
+		return
+	}
+
+	s.serve(w, r)
+}
+
+// serve routes a single call to its handler.
+func (s *LibraryRouter) serve(w http.ResponseWriter, r *http.Request) {
+	c := s.codecBuilder()
+
+	route, err := c.Route(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+
+	handler, ok := s.routes[route]
+	// only routes which are the URL path are matched against path templates, JSON-RPC methods are not
+	if !ok && route == r.URL.Path {
+		pattern, params, matched := s.matcher.Match(r.Method, route)
+		if matched {
+			route = pattern
+			handler, ok = s.routes[route]
+			r = r.WithContext(runtime.WithRoute(r.Context(), pattern, params))
+		}
+	}
+	if !ok {
+		c.WriteError(w, &codec.RouteNotFoundError{Route: route})
+		return
+	}
+	// the responses of streams cannot be written into the response of a batch
+	if s.streams[route] && codec.InBatch(r.Context()) {
+		c.WriteError(w, &codec.StreamInBatchError{Route: route})
+		return
+	}
+	if s.headerMatcher != nil {
+		r = r.WithContext(runtime.IncomingContext(r.Context(), r.Header, s.headerMatcher))
+	}
+	// the handler goes on with the codec which read the route, as it holds the state of the request
+	r = r.WithContext(codec.NewContext(r.Context(), c))
+
+	handler(w, r)
+}
+
+type httpLibraryServer struct {
+	srv          LibraryServer
+	codecBuilder codec.CodecBuilder
+}
+
+func newHTTPLibraryServer(srv LibraryServer, codecBuilder codec.CodecBuilder) *httpLibraryServer {
+	return &httpLibraryServer{
+		srv:          srv,
+		codecBuilder: codecBuilder,
+	}
+}
+
+// codecFor returns the codec the router read the route of the request with, a new one
+// if the handler is called otherwise. Codecs are never shared between requests.
+func (s *httpLibraryServer) codecFor(r *http.Request) codec.Codec {
+	if cdc, ok := codec.FromContext(r.Context()); ok {
+		return cdc
+	}
+	return s.codecBuilder()
+}
+
+func (s *httpLibraryServer) GetBook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	cdc := s.codecFor(r)
+	arg := GetBookRequest{}
+	err := s.readGetBook(r, cdc, &arg)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	ctx, transport := runtime.NewServerTransportStream(r.Context(), "/bindings.Library/GetBook")
+	grpcResp, err := s.srv.GetBook(ctx, &arg)
+	transport.WriteHeader(w)
+	transport.WriteTrailer(w)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	cdc.WriteResponse(w, grpcResp)
+}
+
+var (
+	filter_Library_GetBook_0 = runtime.NewFilter("name")
+	filter_Library_GetBook_1 = runtime.NewFilter("id")
+)
+
+// readGetBook reads the request body, the path and the query parameters as the matched route defines.
+func (s *httpLibraryServer) readGetBook(r *http.Request, cdc codec.Codec, arg *GetBookRequest) error {
+	var err error
+
+	route, _ := runtime.RouteFromContext(r.Context())
+	switch route {
+	case "GET /v1/{name=shelves/*/books/*}":
+		params := runtime.PathParams(r.Context())
+		if val, ok := params["name"]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing parameter %s", "name")
+		} else if arg.Name, err = runtime.String(val); err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", "name", err)
+		}
+		if err = runtime.PopulateQueryParameters(arg, r.URL.Query(), filter_Library_GetBook_0); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	case "GET /v1/books/{id}":
+		params := runtime.PathParams(r.Context())
+		if val, ok := params["id"]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing parameter %s", "id")
+		} else if arg.Id, err = runtime.Int64(val); err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", "id", err)
+		}
+		if err = runtime.PopulateQueryParameters(arg, r.URL.Query(), filter_Library_GetBook_1); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	default:
+		return cdc.ReadRequest(r, arg)
+	}
+
+	return nil
+}
+
+func (s *httpLibraryServer) CreateBook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	cdc := s.codecFor(r)
+	arg := CreateBookRequest{}
+	err := s.readCreateBook(r, cdc, &arg)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	ctx, transport := runtime.NewServerTransportStream(r.Context(), "/bindings.Library/CreateBook")
+	grpcResp, err := s.srv.CreateBook(ctx, &arg)
+	transport.WriteHeader(w)
+	transport.WriteTrailer(w)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	cdc.WriteResponse(w, grpcResp)
+}
+
+var (
+	filter_Library_CreateBook_0 = runtime.NewFilter("parent", "book")
+	filter_Library_CreateBook_1 = runtime.NewFilter()
+)
+
+// readCreateBook reads the request body, the path and the query parameters as the matched route defines.
+func (s *httpLibraryServer) readCreateBook(r *http.Request, cdc codec.Codec, arg *CreateBookRequest) error {
+	var err error
+
+	route, _ := runtime.RouteFromContext(r.Context())
+	switch route {
+	case "POST /v1/{parent=shelves/*}/books":
+		if err = cdc.ReadRequest(r, codec.Field{Message: arg, Name: "book", JSONName: "book"}); err != nil {
+			return err
+		}
+		params := runtime.PathParams(r.Context())
+		if val, ok := params["parent"]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing parameter %s", "parent")
+		} else if arg.Parent, err = runtime.String(val); err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", "parent", err)
+		}
+		if err = runtime.PopulateQueryParameters(arg, r.URL.Query(), filter_Library_CreateBook_0); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	case "PUT /v1/books":
+		if err = cdc.ReadRequest(r, arg); err != nil {
+			return err
+		}
+	default:
+		return cdc.ReadRequest(r, arg)
+	}
+
+	return nil
+}
+
+func (s *httpLibraryServer) TagBook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	cdc := s.codecFor(r)
+	arg := TagBookRequest{}
+	err := s.readTagBook(r, cdc, &arg)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	ctx, transport := runtime.NewServerTransportStream(r.Context(), "/bindings.Library/TagBook")
+	grpcResp, err := s.srv.TagBook(ctx, &arg)
+	transport.WriteHeader(w)
+	transport.WriteTrailer(w)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	cdc.WriteResponse(w, grpcResp)
+}
+
+var (
+	filter_Library_TagBook_0 = runtime.NewFilter("name", "tags")
+)
+
+// readTagBook reads the request body, the path and the query parameters as the matched route defines.
+func (s *httpLibraryServer) readTagBook(r *http.Request, cdc codec.Codec, arg *TagBookRequest) error {
+	var err error
+
+	route, _ := runtime.RouteFromContext(r.Context())
+	switch route {
+	case "POST /v1/{name=shelves/*/books/*}/tags":
+		if err = cdc.ReadRequest(r, codec.Field{Message: arg, Name: "tags", JSONName: "tags"}); err != nil {
+			return err
+		}
+		params := runtime.PathParams(r.Context())
+		if val, ok := params["name"]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing parameter %s", "name")
+		} else if arg.Name, err = runtime.String(val); err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", "name", err)
+		}
+		if err = runtime.PopulateQueryParameters(arg, r.URL.Query(), filter_Library_TagBook_0); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	default:
+		return cdc.ReadRequest(r, arg)
+	}
+
+	return nil
+}
+
+func (s *httpLibraryServer) ListBooks(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	cdc := s.codecFor(r)
+	arg := ListBooksRequest{}
+	err := s.readListBooks(r, cdc, &arg)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	ctx, transport := runtime.NewServerTransportStream(r.Context(), "/bindings.Library/ListBooks")
+	grpcResp, err := s.srv.ListBooks(ctx, &arg)
+	transport.WriteHeader(w)
+	transport.WriteTrailer(w)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	route, _ := runtime.RouteFromContext(r.Context())
+	switch route {
+	case "GET /v1/{parent=shelves/*}/books":
+		cdc.WriteResponse(w, codec.Field{Message: grpcResp, Name: "books", JSONName: "books"})
+		return
+	case "GET /v1/{parent=shelves/*}/next":
+		cdc.WriteResponse(w, codec.Field{Message: grpcResp, Name: "next_page_token", JSONName: "nextPageToken"})
+		return
+	}
+
+	cdc.WriteResponse(w, grpcResp)
+}
+
+var (
+	filter_Library_ListBooks_0 = runtime.NewFilter("parent")
+	filter_Library_ListBooks_1 = runtime.NewFilter("parent")
+	filter_Library_ListBooks_2 = runtime.NewFilter("parent")
+)
+
+// readListBooks reads the request body, the path and the query parameters as the matched route defines.
+func (s *httpLibraryServer) readListBooks(r *http.Request, cdc codec.Codec, arg *ListBooksRequest) error {
+	var err error
+
+	route, _ := runtime.RouteFromContext(r.Context())
+	switch route {
+	case "GET /v1/{parent=shelves/*}/books":
+		params := runtime.PathParams(r.Context())
+		if val, ok := params["parent"]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing parameter %s", "parent")
+		} else if arg.Parent, err = runtime.String(val); err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", "parent", err)
+		}
+		if err = runtime.PopulateQueryParameters(arg, r.URL.Query(), filter_Library_ListBooks_0); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	case "GET /v1/{parent=shelves/*}/next":
+		params := runtime.PathParams(r.Context())
+		if val, ok := params["parent"]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing parameter %s", "parent")
+		} else if arg.Parent, err = runtime.String(val); err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", "parent", err)
+		}
+		if err = runtime.PopulateQueryParameters(arg, r.URL.Query(), filter_Library_ListBooks_1); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	case "GET /v2/{parent=shelves/*}/books":
+		params := runtime.PathParams(r.Context())
+		if val, ok := params["parent"]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing parameter %s", "parent")
+		} else if arg.Parent, err = runtime.String(val); err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", "parent", err)
+		}
+		if err = runtime.PopulateQueryParameters(arg, r.URL.Query(), filter_Library_ListBooks_2); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	default:
+		return cdc.ReadRequest(r, arg)
+	}
+
+	return nil
+}
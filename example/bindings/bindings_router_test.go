@@ -0,0 +1,240 @@
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/lazada/protoc-gen-go-http/codec"
+)
+
+// newRouter serves mock with the REST codec.
+func newRouter(t *testing.T, mock *LibraryMock) *LibraryRouter {
+	router, err := NewLibraryRouter(mock, func() codec.Codec { return codec.NewRESTCCodec() })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func TestRouterBindings(t *testing.T) {
+	book := &Book{Name: "shelves/1/books/2", Title: "Dune", Pages: 412}
+	mock := NewLibraryMock().
+		ReturnGetBook(book, nil).
+		ReturnCreateBook(book, nil).
+		ReturnTagBook(book, nil)
+	router := newRouter(t, mock)
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		// call is the method called and want the request it gets, nil if none is called
+		call string
+		want proto.Message
+		// status is the status of the response, 200 if unset
+		status int
+	}{
+		{
+			name:   "path variable",
+			method: http.MethodGet,
+			path:   "/v1/shelves/1/books/2?view=full",
+			call:   "GetBook",
+			want:   &GetBookRequest{Name: "shelves/1/books/2", View: "full"},
+		},
+		{
+			name:   "path variable not overridden by the query",
+			method: http.MethodGet,
+			path:   "/v1/shelves/1/books/2?name=other&view=full",
+			call:   "GetBook",
+			want:   &GetBookRequest{Name: "shelves/1/books/2", View: "full"},
+		},
+		{
+			name:   "additional binding, converted path variable",
+			method: http.MethodGet,
+			path:   "/v1/books/42?view=basic",
+			call:   "GetBook",
+			want:   &GetBookRequest{Id: 42, View: "basic"},
+		},
+		{
+			name:   "additional binding, mistyped path variable",
+			method: http.MethodGet,
+			path:   "/v1/books/dune",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "body field",
+			method: http.MethodPost,
+			path:   "/v1/shelves/1/books?requestId=r1",
+			body:   `{"title": "Dune", "pages": "412"}`,
+			call:   "CreateBook",
+			want:   &CreateBookRequest{Parent: "shelves/1", Book: &Book{Title: "Dune", Pages: 412}, RequestId: "r1"},
+		},
+		{
+			name:   "body field not overridden by the query",
+			method: http.MethodPost,
+			path:   "/v1/shelves/1/books?book.title=other",
+			body:   `{"title": "Dune"}`,
+			call:   "CreateBook",
+			want:   &CreateBookRequest{Parent: "shelves/1", Book: &Book{Title: "Dune"}},
+		},
+		{
+			name:   "additional binding, whole body",
+			method: http.MethodPut,
+			path:   "/v1/books?requestId=ignored",
+			body:   `{"parent": "shelves/2", "book": {"title": "Dune"}, "requestId": "r2"}`,
+			call:   "CreateBook",
+			want:   &CreateBookRequest{Parent: "shelves/2", Book: &Book{Title: "Dune"}, RequestId: "r2"},
+		},
+		{
+			name:   "repeated body field",
+			method: http.MethodPost,
+			path:   "/v1/shelves/1/books/2/tags?replace=true",
+			body:   `["scifi", "classic"]`,
+			call:   "TagBook",
+			want:   &TagBookRequest{Name: "shelves/1/books/2", Tags: []string{"scifi", "classic"}, Replace: true},
+		},
+		{
+			name:   "mistyped body field",
+			method: http.MethodPost,
+			path:   "/v1/shelves/1/books/2/tags",
+			body:   `{"tags": ["scifi"]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "wrong HTTP method",
+			method: http.MethodDelete,
+			path:   "/v1/shelves/1/books/2",
+			status: http.StatusNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.MockReset()
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+
+			status := tc.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if rec.Code != status {
+				t.Fatalf("want status %d, got %d: %s", status, rec.Code, rec.Body)
+			}
+
+			calls := mock.MockCalls()
+			if tc.call == "" {
+				if len(calls) != 0 {
+					t.Fatalf("want no call, got %v", calls)
+				}
+				return
+			}
+			if len(calls) != 1 || calls[0].Method != tc.call {
+				t.Fatalf("want a call to %s, got %v", tc.call, calls)
+			}
+			if got := calls[0].Requests[0]; !proto.Equal(got, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRouterResponseBody(t *testing.T) {
+	resp := &ListBooksResponse{
+		Books:         []*Book{{Name: "shelves/1/books/1", Pages: 10}, {Name: "shelves/1/books/2"}},
+		NextPageToken: 9007199254740993,
+	}
+	mock := NewLibraryMock().OnListBooks(func(ctx context.Context, in *ListBooksRequest) (*ListBooksResponse, error) {
+		if in.Parent != "shelves/1" || in.PageSize != 2 {
+			t.Errorf("want the parent and the page size, got %v", in)
+		}
+		return resp, nil
+	})
+	router := newRouter(t, mock)
+
+	for _, tc := range []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "repeated field",
+			path: "/v1/shelves/1/books?pageSize=2",
+			want: `[{"name": "shelves/1/books/1", "pages": "10"}, {"name": "shelves/1/books/2"}]`,
+		},
+		{
+			name: "scalar field",
+			path: "/v1/shelves/1/next?pageSize=2",
+			want: `"9007199254740993"`,
+		},
+		{
+			name: "whole response",
+			path: "/v2/shelves/1/books?pageSize=2",
+			want: `{"books": [{"name": "shelves/1/books/1", "pages": "10"}, {"name": "shelves/1/books/2"}], "nextPageToken": "9007199254740993"}`,
+		},
+		{
+			name: "route of the method",
+			path: "/library/listbooks?parent=shelves/1&pageSize=2",
+			want: `{"books": [{"name": "shelves/1/books/1", "pages": "10"}, {"name": "shelves/1/books/2"}], "nextPageToken": "9007199254740993"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("want status 200, got %d: %s", rec.Code, rec.Body)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%v: %s", err, rec.Body)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("want %s, got %s", tc.want, rec.Body)
+			}
+		})
+	}
+}
+
+// TestRouterAdditionalBindings checks that every binding of a method is routed to its handler.
+func TestRouterAdditionalBindings(t *testing.T) {
+	mock := NewLibraryMock().
+		ReturnGetBook(&Book{}, nil).
+		ReturnCreateBook(&Book{}, nil).
+		ReturnListBooks(&ListBooksResponse{}, nil)
+	router := newRouter(t, mock)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		call   string
+	}{
+		{http.MethodGet, "/v1/shelves/1/books/2", "GetBook"},
+		{http.MethodGet, "/v1/books/2", "GetBook"},
+		{http.MethodPost, "/v1/shelves/1/books", "CreateBook"},
+		{http.MethodPut, "/v1/books", "CreateBook"},
+		{http.MethodGet, "/v1/shelves/1/books", "ListBooks"},
+		{http.MethodGet, "/v1/shelves/1/next", "ListBooks"},
+		{http.MethodGet, "/v2/shelves/1/books", "ListBooks"},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			mock.MockReset()
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader("{}")))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("want status 200, got %d: %s", rec.Code, rec.Body)
+			}
+			if calls := mock.MockCallsTo(tc.call); len(calls) != 1 {
+				t.Fatalf("want a call to %s, got %v", tc.call, mock.MockCalls())
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package example
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/lazada/protoc-gen-go-http/codec"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = codes.OK
+var _ = status.Errorf
+
+type httpExampleClient struct {
+	baseURL string
+	client  *http.Client
+	cdc     codec.ClientCodec
+}
+
+// NewExampleHTTPClient returns ExampleClient calling the ExampleRouter served at baseURL.
+// Call options are ignored. Server-streaming methods ask for newline-delimited JSON,
+// client-streaming and bidirectional streaming ones are not supported over HTTP.
+func NewExampleHTTPClient(baseURL string, httpClient *http.Client, cdc codec.ClientCodec) ExampleClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &httpExampleClient{
+		baseURL: baseURL,
+		client:  httpClient,
+		cdc:     cdc,
+	}
+}
+
+func (c *httpExampleClient) GetPerson(ctx context.Context, in *Query, opts ...grpc.CallOption) (*Person, error) {
+	out := new(Person)
+	if err := codec.Invoke(ctx, c.client, c.cdc, c.baseURL, "/example/getperson", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *httpExampleClient) ListPeople(ctx context.Context, in *Query, opts ...grpc.CallOption) (Example_ListPeopleClient, error) {
+	stream, err := codec.InvokeStream(ctx, c.client, c.cdc, c.baseURL, "/example/listpeople", in)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpExampleListPeopleClient{stream}, nil
+}
+
+type httpExampleListPeopleClient struct {
+	grpc.ClientStream
+}
+
+func (x *httpExampleListPeopleClient) Recv() (*Person, error) {
+	m := new(Person)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
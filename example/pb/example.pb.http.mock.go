@@ -0,0 +1,163 @@
+package example
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = io.EOF
+var _ context.Context
+
+// ExampleMockCall is a call received by ExampleMock.
+type ExampleMockCall struct {
+	// Method is the name of the called method as in the service definition.
+	Method string
+	// Metadata is the incoming metadata of the call.
+	Metadata metadata.MD
+	// Requests are the request messages received so far, a single one for unary
+	// and server-streaming methods.
+	Requests []proto.Message
+}
+
+// ExampleMock is a ExampleServer answering with canned or scripted responses,
+// which records the calls it receives. Methods without responses return an Unimplemented error.
+// Serve it with NewExampleRouter to test consumers against the real codec and routes.
+type ExampleMock struct {
+	// state is a field of its own, so that it cannot collide with the methods of the service
+	state struct {
+		mu    sync.Mutex
+		calls []*ExampleMockCall
+
+		onGetPerson  func(ctx context.Context, in *Query) (*Person, error)
+		onListPeople func(in *Query, stream Example_ListPeopleServer) error
+	}
+}
+
+// NewExampleMock returns a ExampleMock without responses.
+func NewExampleMock() *ExampleMock {
+	return &ExampleMock{}
+}
+
+// MockCalls returns the calls received so far, in order.
+func (m *ExampleMock) MockCalls() []ExampleMockCall {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	calls := make([]ExampleMockCall, len(m.state.calls))
+	for i, call := range m.state.calls {
+		calls[i] = *call
+		calls[i].Requests = append([]proto.Message(nil), call.Requests...)
+	}
+	return calls
+}
+
+// MockCallsTo returns the calls of the method received so far, in order.
+func (m *ExampleMock) MockCallsTo(method string) []ExampleMockCall {
+	var calls []ExampleMockCall
+	for _, call := range m.MockCalls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// MockReset forgets the calls received so far. Responses are kept.
+func (m *ExampleMock) MockReset() {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.calls = nil
+}
+
+func (m *ExampleMock) record(ctx context.Context, method string, reqs ...proto.Message) *ExampleMockCall {
+	md, _ := metadata.FromIncomingContext(ctx)
+	call := &ExampleMockCall{
+		Method:   method,
+		Metadata: md,
+		Requests: reqs,
+	}
+
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.calls = append(m.state.calls, call)
+	return call
+}
+
+func (m *ExampleMock) recordRequest(call *ExampleMockCall, req proto.Message) {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	call.Requests = append(call.Requests, req)
+}
+
+// OnGetPerson scripts GetPerson with f, which serves every call after it is recorded.
+func (m *ExampleMock) OnGetPerson(f func(ctx context.Context, in *Query) (*Person, error)) *ExampleMock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.onGetPerson = f
+	return m
+}
+
+// ReturnGetPerson makes GetPerson answer every call with resp and err.
+func (m *ExampleMock) ReturnGetPerson(resp *Person, err error) *ExampleMock {
+	return m.OnGetPerson(func(context.Context, *Query) (*Person, error) {
+		return resp, err
+	})
+}
+
+func (m *ExampleMock) GetPerson(ctx context.Context, in *Query) (*Person, error) {
+	m.record(ctx, "GetPerson", in)
+
+	m.state.mu.Lock()
+	f := m.state.onGetPerson
+	m.state.mu.Unlock()
+	if f == nil {
+		return nil, status.Errorf(codes.Unimplemented, "method GetPerson is not mocked")
+	}
+
+	return f(ctx, in)
+}
+
+// OnListPeople scripts ListPeople with f, which serves every call after it is recorded.
+func (m *ExampleMock) OnListPeople(f func(in *Query, stream Example_ListPeopleServer) error) *ExampleMock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.onListPeople = f
+	return m
+}
+
+// ReturnListPeople makes ListPeople send resps and then return err on every call.
+func (m *ExampleMock) ReturnListPeople(resps []*Person, err error) *ExampleMock {
+	return m.OnListPeople(func(in *Query, stream Example_ListPeopleServer) error {
+		for _, resp := range resps {
+			if sendErr := stream.Send(resp); sendErr != nil {
+				return sendErr
+			}
+		}
+		return err
+	})
+}
+
+func (m *ExampleMock) ListPeople(in *Query, stream Example_ListPeopleServer) error {
+	m.record(stream.Context(), "ListPeople", in)
+
+	m.state.mu.Lock()
+	f := m.state.onListPeople
+	m.state.mu.Unlock()
+	if f == nil {
+		return status.Errorf(codes.Unimplemented, "method ListPeople is not mocked")
+	}
+
+	return f(in, stream)
+}
@@ -2,15 +2,27 @@ package example
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/lazada/protoc-gen-go-http/codec"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// Reference imports to suppress errors if they are not otherwise used.
+var _ grpc.ServerStream
+var _ = codes.OK
+var _ = status.Errorf
+
 type options struct {
-	routes      map[string]http.HandlerFunc
-	withSwagger bool
+	routes           map[string]http.HandlerFunc
+	withSwagger      bool
+	headerMatcher    runtime.HeaderMatcher
+	batchConcurrency int
+	maxBatchLength   int
+	methodNaming     runtime.MethodNaming
 }
 
 func (o *options) validate() error {
@@ -23,7 +35,15 @@ func (o *options) validate() error {
 
 type option func(*options)
 
+// SwaggerPath is the path the Swagger document is served at with WithSwagger.
+const SwaggerPath = "/swagger.json"
+
+// swaggerJSON is the Swagger document of the services defined in the file.
+var swaggerJSON = []byte("{\"swagger\":\"2.0\",\"info\":{\"title\":\"example.proto\",\"version\":\"version not set\"},\"tags\":[{\"name\":\"Example\"}],\"consumes\":[\"application/json\"],\"produces\":[\"application/json\"],\"paths\":{\"/example/getperson\":{\"post\":{\"summary\":\"Simple request/response.\",\"operationId\":\"Example_GetPerson\",\"tags\":[\"Example\"],\"parameters\":[{\"name\":\"body\",\"in\":\"body\",\"required\":true,\"schema\":{\"$ref\":\"#/definitions/Query\"}}],\"responses\":{\"200\":{\"description\":\"A successful response.\",\"schema\":{\"$ref\":\"#/definitions/Person\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}},\"/example/listpeople\":{\"post\":{\"summary\":\"Server streaming (ignored).\",\"operationId\":\"Example_ListPeople\",\"tags\":[\"Example\"],\"produces\":[\"text/event-stream\",\"application/x-ndjson\"],\"parameters\":[{\"name\":\"body\",\"in\":\"body\",\"required\":true,\"schema\":{\"$ref\":\"#/definitions/Query\"}}],\"responses\":{\"200\":{\"description\":\"A stream of messages sent as Server-Sent Events, or as newline-delimited JSON if accepted.\",\"schema\":{\"$ref\":\"#/definitions/Person\"}},\"default\":{\"description\":\"An error.\",\"schema\":{\"$ref\":\"#/definitions/httpError\"}}}}}},\"definitions\":{\"Person\":{\"type\":\"object\",\"properties\":{\"age\":{\"type\":\"integer\",\"format\":\"int32\"},\"name\":{\"type\":\"string\"}}},\"Query\":{\"type\":\"object\",\"properties\":{\"ageFrom\":{\"type\":\"integer\",\"format\":\"int32\"},\"ageTo\":{\"type\":\"integer\",\"format\":\"int32\"},\"name\":{\"type\":\"string\"}}},\"httpError\":{\"type\":\"object\",\"properties\":{\"code\":{\"description\":\"The gRPC status code.\",\"type\":\"integer\",\"format\":\"int32\"},\"details\":{\"description\":\"The error details as typed JSON Any objects.\",\"type\":\"array\",\"items\":{\"type\":\"object\",\"properties\":{\"@type\":{\"type\":\"string\"}}}},\"error\":{\"type\":\"string\"},\"message\":{\"type\":\"string\"}}}}}")
+
 // WithRoutes sets handlers to specific routes.
+// Routes of the "<HTTP method> <path template>" form, e.g. "GET /v1/{name=books/*}",
+// are matched against the request method and path.
 // Set the handler to nil to delete a route.
 func WithRoutes(routes map[string]http.HandlerFunc) option {
 	return func(opts *options) {
@@ -31,27 +51,69 @@ func WithRoutes(routes map[string]http.HandlerFunc) option {
 	}
 }
 
+// WithSwagger serves the Swagger document of the services at SwaggerPath.
 func WithSwagger() option {
 	return func(opts *options) {
 		opts.withSwagger = true
 	}
 }
 
+// WithHeaderMatcher sets which request headers are forwarded into the incoming gRPC metadata
+// of the methods, runtime.DefaultHeaderMatcher by default. A nil matcher forwards no headers.
+func WithHeaderMatcher(m runtime.HeaderMatcher) option {
+	return func(opts *options) {
+		opts.headerMatcher = m
+	}
+}
+
+// WithMethodNaming sets the naming scheme of the methods in JSON-RPC requests, runtime.PathNaming by default.
+// Methods can always be called by their routes, e.g. "/example/getperson", as well.
+func WithMethodNaming(naming runtime.MethodNaming) option {
+	return func(opts *options) {
+		opts.methodNaming = naming
+	}
+}
+
+// WithBatchConcurrency serves up to n calls of a batch request, e.g. a JSON-RPC batch,
+// concurrently. They are served one at a time by default.
+func WithBatchConcurrency(n int) option {
+	return func(opts *options) {
+		opts.batchConcurrency = n
+	}
+}
+
+// WithMaxBatchLength rejects batch requests of more than n calls, codec.DefaultMaxBatchLength by default.
+// Their length is not limited if n is less than 1.
+func WithMaxBatchLength(n int) option {
+	return func(opts *options) {
+		opts.maxBatchLength = n
+	}
+}
+
 type ExampleRouter struct {
-	srv          *httpExampleServer
-	codecBuilder codec.CodecBuilder
-	routes       map[string]http.HandlerFunc
+	srv              *httpExampleServer
+	codecBuilder     codec.CodecBuilder
+	routes           map[string]http.HandlerFunc
+	streams          map[string]bool
+	matcher          *runtime.Matcher
+	headerMatcher    runtime.HeaderMatcher
+	batchConcurrency int
+	maxBatchLength   int
+	withSwagger      bool
 }
 
 func NewExampleRouter(srv ExampleServer, codecBuilder codec.CodecBuilder, opts ...option) (*ExampleRouter, error) {
 	out := &ExampleRouter{
-		srv:          newHTTPExampleServer(srv, codecBuilder()),
+		srv:          newHTTPExampleServer(srv, codecBuilder),
 		codecBuilder: codecBuilder,
 		routes:       make(map[string]http.HandlerFunc),
 	}
 
 	defaultOptions := &options{
-		routes: make(map[string]http.HandlerFunc),
+		routes:         make(map[string]http.HandlerFunc),
+		headerMatcher:  runtime.DefaultHeaderMatcher,
+		maxBatchLength: codec.DefaultMaxBatchLength,
+		methodNaming:   runtime.PathNaming,
 	}
 
 	for _, opt := range opts {
@@ -61,9 +123,25 @@ func NewExampleRouter(srv ExampleServer, codecBuilder codec.CodecBuilder, opts .
 	if err := defaultOptions.validate(); err != nil {
 		return nil, err
 	}
+	out.withSwagger = defaultOptions.withSwagger
+	out.headerMatcher = defaultOptions.headerMatcher
+	out.batchConcurrency = defaultOptions.batchConcurrency
+	out.maxBatchLength = defaultOptions.maxBatchLength
 
 	out.routes = map[string]http.HandlerFunc{
-		"/example/getperson": out.srv.GetPerson,
+		"/example/getperson":  out.srv.GetPerson,
+		"/example/listpeople": out.srv.ListPeople,
+	}
+
+	// the routes of streaming methods, which cannot be called in batches
+	out.streams = map[string]bool{
+		"/example/listpeople": true,
+	}
+
+	if naming := defaultOptions.methodNaming; naming != nil {
+		out.routes[naming("/Example/GetPerson")] = out.srv.GetPerson
+		out.routes[naming("/Example/ListPeople")] = out.srv.ListPeople
+		out.streams[naming("/Example/ListPeople")] = true
 	}
 
 	for route, handler := range defaultOptions.routes {
@@ -71,12 +149,39 @@ func NewExampleRouter(srv ExampleServer, codecBuilder codec.CodecBuilder, opts .
 			continue
 		}
 		out.routes[route] = handler
+		delete(out.streams, route)
 	}
 
+	routes := make([]string, 0, len(out.routes))
+	for route := range out.routes {
+		routes = append(routes, route)
+	}
+
+	matcher, err := runtime.NewMatcher(routes)
+	if err != nil {
+		return nil, err
+	}
+	out.matcher = matcher
+
 	return out, nil
 }
 
 func (s *ExampleRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.withSwagger && swaggerJSON != nil && r.Method == http.MethodGet && r.URL.Path == SwaggerPath {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(swaggerJSON)
+		return
+	}
+
+	if codec.ServeBatch(w, r, s.codecBuilder, http.HandlerFunc(s.serve), s.batchConcurrency, s.maxBatchLength) {
+		return
+	}
+
+	s.serve(w, r)
+}
+
+// serve routes a single call to its handler.
+func (s *ExampleRouter) serve(w http.ResponseWriter, r *http.Request) {
 	c := s.codecBuilder()
 
 	route, err := c.Route(r)
@@ -86,40 +191,99 @@ func (s *ExampleRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	handler, ok := s.routes[route]
+	// only routes which are the URL path are matched against path templates, JSON-RPC methods are not
+	if !ok && route == r.URL.Path {
+		pattern, params, matched := s.matcher.Match(r.Method, route)
+		if matched {
+			route = pattern
+			handler, ok = s.routes[route]
+			r = r.WithContext(runtime.WithRoute(r.Context(), pattern, params))
+		}
+	}
 	if !ok {
-		c.WriteError(w, fmt.Errorf("no handler for route %s", route))
+		c.WriteError(w, &codec.RouteNotFoundError{Route: route})
 		return
 	}
+	// the responses of streams cannot be written into the response of a batch
+	if s.streams[route] && codec.InBatch(r.Context()) {
+		c.WriteError(w, &codec.StreamInBatchError{Route: route})
+		return
+	}
+	if s.headerMatcher != nil {
+		r = r.WithContext(runtime.IncomingContext(r.Context(), r.Header, s.headerMatcher))
+	}
+	// the handler goes on with the codec which read the route, as it holds the state of the request
+	r = r.WithContext(codec.NewContext(r.Context(), c))
 
 	handler(w, r)
 }
 
 type httpExampleServer struct {
-	srv ExampleServer
-	cdc codec.Codec
+	srv          ExampleServer
+	codecBuilder codec.CodecBuilder
 }
 
-func newHTTPExampleServer(srv ExampleServer, cdc codec.Codec) *httpExampleServer {
+func newHTTPExampleServer(srv ExampleServer, codecBuilder codec.CodecBuilder) *httpExampleServer {
 	return &httpExampleServer{
-		srv: srv,
-		cdc: cdc,
+		srv:          srv,
+		codecBuilder: codecBuilder,
+	}
+}
+
+// codecFor returns the codec the router read the route of the request with, a new one
+// if the handler is called otherwise. Codecs are never shared between requests.
+func (s *httpExampleServer) codecFor(r *http.Request) codec.Codec {
+	if cdc, ok := codec.FromContext(r.Context()); ok {
+		return cdc
 	}
+	return s.codecBuilder()
 }
 
 func (s *httpExampleServer) GetPerson(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
+	cdc := s.codecFor(r)
 	arg := Query{}
-	err := s.cdc.ReadRequest(r, &arg)
+	err := cdc.ReadRequest(r, &arg)
 	if err != nil {
-		s.cdc.WriteError(w, err)
+		cdc.WriteError(w, err)
 		return
 	}
 
-	grpcResp, err := s.srv.GetPerson(r.Context(), &arg)
+	ctx, transport := runtime.NewServerTransportStream(r.Context(), "/Example/GetPerson")
+	grpcResp, err := s.srv.GetPerson(ctx, &arg)
+	transport.WriteHeader(w)
+	transport.WriteTrailer(w)
 	if err != nil {
-		s.cdc.WriteError(w, err)
+		cdc.WriteError(w, err)
 		return
 	}
 
-	s.cdc.WriteResponse(w, grpcResp)
+	cdc.WriteResponse(w, grpcResp)
+}
+
+func (s *httpExampleServer) ListPeople(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	cdc := s.codecFor(r)
+	arg := Query{}
+	err := cdc.ReadRequest(r, &arg)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	stream, err := runtime.NewServerStream(w, r, cdc, "/Example/ListPeople")
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	stream.Finish(s.srv.ListPeople(&arg, &httpExampleListPeopleServer{stream}))
+}
+
+type httpExampleListPeopleServer struct {
+	grpc.ServerStream
+}
+
+func (x *httpExampleListPeopleServer) Send(m *Person) error {
+	return x.ServerStream.SendMsg(m)
 }
@@ -0,0 +1,173 @@
+package example
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/lazada/protoc-gen-go-http/codec"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// clientCodecs are the codecs the HTTP client is tested with, along with the codecs of the router.
+var clientCodecs = []struct {
+	name   string
+	server codec.CodecBuilder
+	client func() codec.ClientCodec
+}{
+	{"REST", func() codec.Codec { return codec.NewRESTCCodec() }, codec.NewRESTClientCodec},
+	{"JSON-RPC", func() codec.Codec { return codec.NewJsonRPCCodec() }, codec.NewJsonRPCClientCodec},
+}
+
+// serveClient serves the mock with the router and returns a client of it.
+func serveClient(t *testing.T, mock *ExampleMock, server codec.CodecBuilder, client codec.ClientCodec) ExampleClient {
+	router, err := NewExampleRouter(mock, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	return NewExampleHTTPClient(srv.URL, srv.Client(), client)
+}
+
+func TestClientInvoke(t *testing.T) {
+	for _, cc := range clientCodecs {
+		t.Run(cc.name, func(t *testing.T) {
+			mock := NewExampleMock().OnGetPerson(func(ctx context.Context, in *Query) (*Person, error) {
+				md, _ := metadata.FromIncomingContext(ctx)
+				if got := md.Get("request-id"); len(got) != 1 || got[0] != "42" {
+					return nil, status.Errorf(codes.InvalidArgument, "want the request-id metadata, got %q", got)
+				}
+				return &Person{Name: in.Name, Age: in.AgeFrom}, nil
+			})
+			client := serveClient(t, mock, cc.server, cc.client())
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "request-id", "42")
+			got, err := client.GetPerson(ctx, &Query{Name: "bob", AgeFrom: 30})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (&Person{Name: "bob", Age: 30}); !proto.Equal(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientInvokeError(t *testing.T) {
+	for _, cc := range clientCodecs {
+		t.Run(cc.name, func(t *testing.T) {
+			st, err := status.New(codes.InvalidArgument, "invalid age").WithDetails(&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "ageFrom", Description: "negative"}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := serveClient(t, NewExampleMock().ReturnGetPerson(nil, st.Err()), cc.server, cc.client())
+
+			_, err = client.GetPerson(context.Background(), &Query{AgeFrom: -1})
+			assertStatus(t, err, st)
+		})
+	}
+}
+
+func TestClientInvokeStream(t *testing.T) {
+	people := []*Person{{Name: "alice", Age: 30}, {Name: "bob", Age: 40}}
+
+	for _, cc := range clientCodecs {
+		t.Run(cc.name, func(t *testing.T) {
+			mock := NewExampleMock().OnListPeople(func(in *Query, stream Example_ListPeopleServer) error {
+				grpc.SetHeader(stream.Context(), metadata.Pairs("total", "2"))
+				grpc.SetTrailer(stream.Context(), metadata.Pairs("cursor", "end"))
+				for _, p := range people {
+					if err := stream.Send(p); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			client := serveClient(t, mock, cc.server, cc.client())
+
+			stream, err := client.ListPeople(context.Background(), &Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range people {
+				got, err := stream.Recv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(got, want) {
+					t.Fatalf("want %v, got %v", want, got)
+				}
+			}
+			if _, err := stream.Recv(); err != io.EOF {
+				t.Fatalf("want io.EOF, got %v", err)
+			}
+
+			header, err := stream.Header()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := header.Get("total"); len(got) != 1 || got[0] != "2" {
+				t.Errorf("want the total header metadata, got %q", got)
+			}
+			if got := stream.Trailer().Get("cursor"); len(got) != 1 || got[0] != "end" {
+				t.Errorf("want the cursor trailer metadata, got %q", got)
+			}
+		})
+	}
+}
+
+func TestClientInvokeStreamError(t *testing.T) {
+	st := status.New(codes.ResourceExhausted, "too many people")
+
+	for _, cc := range clientCodecs {
+		t.Run(cc.name, func(t *testing.T) {
+			t.Run("before the first message", func(t *testing.T) {
+				client := serveClient(t, NewExampleMock().ReturnListPeople(nil, st.Err()), cc.server, cc.client())
+
+				stream, err := client.ListPeople(context.Background(), &Query{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = stream.Recv()
+				assertStatus(t, err, st)
+			})
+
+			t.Run("after the first message", func(t *testing.T) {
+				mock := NewExampleMock().ReturnListPeople([]*Person{{Name: "alice"}}, st.Err())
+				client := serveClient(t, mock, cc.server, cc.client())
+
+				stream, err := client.ListPeople(context.Background(), &Query{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := stream.Recv(); err != nil {
+					t.Fatal(err)
+				}
+				_, err = stream.Recv()
+				assertStatus(t, err, st)
+			})
+		})
+	}
+}
+
+// assertStatus fails the test unless err has the status, with the same details.
+func assertStatus(t *testing.T, err error, want *status.Status) {
+	t.Helper()
+	got, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("want a status error, got %v", err)
+	}
+	if !proto.Equal(got.Proto(), want.Proto()) {
+		t.Fatalf("want %v, got %v", want.Proto(), got.Proto())
+	}
+}
@@ -0,0 +1,319 @@
+package example
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lazada/protoc-gen-go-http/codec"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestJsonRPCConformance checks the router against the examples of the JSON-RPC 2.0
+// specification, https://www.jsonrpc.org/specification#examples, and a few more cases.
+// Error messages and data are left out of the comparison, only error codes are compared.
+func TestJsonRPCConformance(t *testing.T) {
+	srv := NewExampleMock().OnGetPerson(func(ctx context.Context, in *Query) (*Person, error) {
+		switch in.Name {
+		case "invalid":
+			return nil, status.Error(codes.InvalidArgument, "invalid name")
+		case "nobody":
+			return nil, status.Error(codes.NotFound, "no such person")
+		case "unimplemented":
+			return nil, status.Error(codes.Unimplemented, "not yet")
+		case "internal":
+			return nil, status.Error(codes.Internal, "failure")
+		case "plain":
+			return nil, errors.New("plain error")
+		}
+		return &Person{Name: in.Name, Age: in.AgeFrom}, nil
+	})
+	router, err := NewExampleRouter(srv, func() codec.Codec { return codec.NewJsonRPCCodec() })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		req  string
+		// want is the expected response, empty if none is expected
+		want string
+	}{
+		{
+			name: "named params",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "bob", "ageFrom": 42}, "id": 3}`,
+			want: `{"jsonrpc": "2.0", "result": {"name": "bob", "age": 42}, "id": 3}`,
+		},
+		{
+			name: "string id",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "bob"}, "id": "abc"}`,
+			want: `{"jsonrpc": "2.0", "result": {"name": "bob"}, "id": "abc"}`,
+		},
+		{
+			name: "null id",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "bob"}, "id": null}`,
+			want: `{"jsonrpc": "2.0", "result": {"name": "bob"}, "id": null}`,
+		},
+		{
+			name: "omitted params",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "id": 1}`,
+			want: `{"jsonrpc": "2.0", "result": {}, "id": 1}`,
+		},
+		{
+			name: "InvalidArgument error",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "invalid"}, "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32602}, "id": 1}`,
+		},
+		{
+			name: "Unimplemented error",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "unimplemented"}, "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32601}, "id": 1}`,
+		},
+		{
+			name: "NotFound error",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "nobody"}, "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32005}, "id": 1}`,
+		},
+		{
+			name: "Internal error",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "internal"}, "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32603}, "id": 1}`,
+		},
+		{
+			name: "error without a status",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "plain"}, "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32002}, "id": 1}`,
+		},
+		{
+			name: "positional params",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": ["bob", 42], "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32602}, "id": 1}`,
+		},
+		{
+			name: "mistyped params",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"ageFrom": "old"}, "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32602}, "id": 1}`,
+		},
+		{
+			name: "notification",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "bob"}}`,
+		},
+		{
+			name: "notification of a non-existent method",
+			req:  `{"jsonrpc": "2.0", "method": "foobar"}`,
+		},
+		{
+			name: "non-existent method",
+			req:  `{"jsonrpc": "2.0", "method": "foobar", "id": "1"}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32601}, "id": "1"}`,
+		},
+		{
+			name: "invalid JSON",
+			req:  `{"jsonrpc": "2.0", "method": "foobar, "params": "bar", "baz]`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32700}, "id": null}`,
+		},
+		{
+			name: "empty body",
+			req:  ``,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32700}, "id": null}`,
+		},
+		{
+			name: "invalid request object",
+			req:  `{"jsonrpc": "2.0", "method": 1, "params": "bar"}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			name: "missing version",
+			req:  `{"method": "/example/getperson", "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			name: "wrong version",
+			req:  `{"jsonrpc": "1.0", "method": "/example/getperson", "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			name: "scalar params",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "params": "bob", "id": 1}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			name: "object id",
+			req:  `{"jsonrpc": "2.0", "method": "/example/getperson", "id": {}}`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			name: "batch, invalid JSON",
+			req:  `[{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"}, {"jsonrpc": "2.0", "method"]`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32700}, "id": null}`,
+		},
+		{
+			name: "empty batch",
+			req:  `[]`,
+			want: `{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			name: "invalid batch",
+			req:  `[1]`,
+			want: `[{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}]`,
+		},
+		{
+			name: "invalid batch of several calls",
+			req:  `[1, 2, 3]`,
+			want: `[
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}
+			]`,
+		},
+		{
+			name: "batch",
+			req: `[
+				{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "a"}, "id": "1"},
+				{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "b"}},
+				{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "c"}, "id": "2"},
+				{"foo": "boo"},
+				{"jsonrpc": "2.0", "method": "foo.get", "params": {"name": "myself"}, "id": "5"},
+				{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"ageFrom": 7}, "id": "9"}
+			]`,
+			want: `[
+				{"jsonrpc": "2.0", "result": {"name": "a"}, "id": "1"},
+				{"jsonrpc": "2.0", "result": {"name": "c"}, "id": "2"},
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32601}, "id": "5"},
+				{"jsonrpc": "2.0", "result": {"age": 7}, "id": "9"}
+			]`,
+		},
+		{
+			name: "batch with a streaming call",
+			req: `[
+				{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "a"}, "id": "1"},
+				{"jsonrpc": "2.0", "method": "/example/listpeople", "params": {"name": "b"}, "id": "2"}
+			]`,
+			want: `[
+				{"jsonrpc": "2.0", "result": {"name": "a"}, "id": "1"},
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": "2"}
+			]`,
+		},
+		{
+			name: "batch of notifications",
+			req: `[
+				{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "a"}},
+				{"jsonrpc": "2.0", "method": "foobar"}
+			]`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.req)))
+
+			if tc.want == "" {
+				if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+					t.Fatalf("want no response, got %d %s", rec.Code, rec.Body)
+				}
+				return
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%v: %s", err, rec.Body)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(withoutErrorDetails(got), want) {
+				t.Fatalf("want %s, got %s", tc.want, rec.Body)
+			}
+		})
+	}
+}
+
+// withoutErrorDetails removes the message and the data of the errors of responses.
+func withoutErrorDetails(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, resp := range v {
+			withoutErrorDetails(resp)
+		}
+	case map[string]interface{}:
+		if e, ok := v["error"].(map[string]interface{}); ok {
+			delete(e, "message")
+			delete(e, "data")
+		}
+	}
+	return v
+}
+
+func TestJsonRPCBatchLimits(t *testing.T) {
+	call := `{"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "bob"}, "id": 1}`
+	batch := func(n int) string {
+		return "[" + strings.TrimSuffix(strings.Repeat(call+",", n), ",") + "]"
+	}
+	srv := NewExampleMock().OnGetPerson(func(ctx context.Context, in *Query) (*Person, error) {
+		if in.Name == "panic" {
+			panic("boom")
+		}
+		return &Person{Name: in.Name}, nil
+	})
+
+	for _, tc := range []struct {
+		name string
+		opts []option
+		req  string
+		// code is the code of the error of the whole batch, 0 if the calls are served
+		code int64
+	}{
+		{name: "default max length", req: batch(codec.DefaultMaxBatchLength)},
+		{name: "over the default max length", req: batch(codec.DefaultMaxBatchLength + 1), code: codec.E_INVALID_REQ},
+		{name: "max length", opts: []option{WithMaxBatchLength(2)}, req: batch(3), code: codec.E_INVALID_REQ},
+		{name: "no max length", opts: []option{WithMaxBatchLength(0)}, req: batch(codec.DefaultMaxBatchLength + 1)},
+		{
+			name: "panic",
+			opts: []option{WithBatchConcurrency(4)},
+			req:  `[` + call + `, {"jsonrpc": "2.0", "method": "/example/getperson", "params": {"name": "panic"}, "id": 2}]`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router, err := NewExampleRouter(srv, func() codec.Codec { return codec.NewJsonRPCCodec() }, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.req)))
+
+			if tc.code != 0 {
+				var resp struct{ Error struct{ Code int64 } }
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("%v: %s", err, rec.Body)
+				}
+				if resp.Error.Code != tc.code {
+					t.Fatalf("want error %d, got %s", tc.code, rec.Body)
+				}
+				return
+			}
+
+			var resps []struct {
+				ID    int
+				Error *struct{ Code int64 }
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resps); err != nil {
+				t.Fatalf("%v: %s", err, rec.Body)
+			}
+			if want := strings.Count(tc.req, `"jsonrpc"`); len(resps) != want {
+				t.Fatalf("want %d responses, got %d", want, len(resps))
+			}
+			for _, resp := range resps {
+				// the call with ID 2 panics
+				if panicked := resp.ID == 2; panicked != (resp.Error != nil) {
+					t.Fatalf("want only the call which panicked to fail, got %s", rec.Body)
+				}
+			}
+		})
+	}
+}
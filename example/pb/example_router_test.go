@@ -0,0 +1,196 @@
+package example
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lazada/protoc-gen-go-http/codec"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TestRouterConcurrentJsonRPC calls the router from many goroutines at once: every response
+// must carry the ID and the params of its own call. Run it with -race.
+func TestRouterConcurrentJsonRPC(t *testing.T) {
+	srv := NewExampleMock().OnGetPerson(func(ctx context.Context, in *Query) (*Person, error) {
+		return &Person{Name: in.Name, Age: in.AgeFrom}, nil
+	})
+	router, err := NewExampleRouter(srv, func() codec.Codec { return codec.NewJsonRPCCodec() })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"/example/getperson","params":{"name":"p%d","ageFrom":%d}}`, i, i, i)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+			var resp struct {
+				ID     int
+				Result struct {
+					Name string
+					Age  int
+				}
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Errorf("call %d: %v: %s", i, err, rec.Body)
+				return
+			}
+			if resp.ID != i || resp.Result.Name != fmt.Sprintf("p%d", i) || resp.Result.Age != i {
+				t.Errorf("call %d: got %s", i, rec.Body)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestRouterPatternRoutes checks that path templates are matched against URL paths,
+// but not against JSON-RPC methods.
+func TestRouterPatternRoutes(t *testing.T) {
+	pattern := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name": %q}`, runtime.PathParams(r.Context())["name"])
+	}
+
+	for _, tc := range []struct {
+		name   string
+		codec  codec.CodecBuilder
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "REST",
+			codec:  func() codec.Codec { return codec.NewRESTCCodec() },
+			path:   "/v1/people/42",
+			status: http.StatusOK,
+			want:   `{"name": "42"}`,
+		},
+		{
+			name:   "REST, no match",
+			codec:  func() codec.Codec { return codec.NewRESTCCodec() },
+			path:   "/v1/people/42/friends",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "JSON-RPC",
+			codec:  func() codec.Codec { return codec.NewJsonRPCCodec() },
+			path:   "/",
+			body:   `{"jsonrpc": "2.0", "method": "/v1/people/42", "id": 1}`,
+			status: http.StatusOK,
+			want:   `{"jsonrpc": "2.0", "error": {"code": -32601}, "id": 1}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router, err := NewExampleRouter(NewExampleMock(), tc.codec, WithRoutes(map[string]http.HandlerFunc{
+				"POST /v1/people/{name}": pattern,
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body)))
+			if rec.Code != tc.status {
+				t.Fatalf("want status %d, got %d %s", tc.status, rec.Code, rec.Body)
+			}
+			if tc.want == "" {
+				return
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%v: %s", err, rec.Body)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(withoutErrorDetails(got), want) {
+				t.Fatalf("want %s, got %s", tc.want, rec.Body)
+			}
+		})
+	}
+}
+
+// TestRouterMetadata checks that the metadata set by methods is sent as Grpc-Metadata-* headers
+// and Grpc-Trailer-* trailers, and that the methods are given their full names.
+func TestRouterMetadata(t *testing.T) {
+	setMetadata := func(ctx context.Context, method string) error {
+		if got, _ := grpc.Method(ctx); got != method {
+			return fmt.Errorf("want method %s, got %s", method, got)
+		}
+		if err := grpc.SetHeader(ctx, metadata.Pairs("total", "2")); err != nil {
+			return err
+		}
+		return grpc.SetTrailer(ctx, metadata.Pairs("cursor", "end", "cursor-bin", "\x00\x01"))
+	}
+	mock := NewExampleMock().OnGetPerson(func(ctx context.Context, in *Query) (*Person, error) {
+		return &Person{}, setMetadata(ctx, "/Example/GetPerson")
+	}).OnListPeople(func(in *Query, stream Example_ListPeopleServer) error {
+		if err := setMetadata(stream.Context(), "/Example/ListPeople"); err != nil {
+			return err
+		}
+		return stream.Send(&Person{})
+	})
+	router, err := NewExampleRouter(mock, func() codec.Codec { return codec.NewRESTCCodec() })
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name   string
+		path   string
+		accept string
+	}{
+		{name: "unary", path: "/example/getperson"},
+		{name: "Server-Sent Events", path: "/example/listpeople"},
+		{name: "newline-delimited JSON", path: "/example/listpeople", accept: runtime.ContentTypeNDJSON},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, srv.URL+tc.path, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept", tc.accept)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("want status 200, got %s: %s", resp.Status, body)
+			}
+
+			if got := resp.Header.Get("Grpc-Metadata-Total"); got != "2" {
+				t.Errorf("want the Grpc-Metadata-Total header, got %q", got)
+			}
+			want := http.Header{
+				"Grpc-Trailer-Cursor":     {"end"},
+				"Grpc-Trailer-Cursor-Bin": {"AAE="},
+			}
+			if !reflect.DeepEqual(resp.Trailer, want) {
+				t.Errorf("want trailers %v, got %v", want, resp.Trailer)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"strings"
+	"text/template"
+)
+
+var (
+	ClientTemplate = template.Must(template.New(`file`).Funcs(template.FuncMap{
+		`lower`: strings.ToLower,
+	}).Parse(`
+package {{ .Package }}
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/lazada/protoc-gen-go-http/codec"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	{{- range .Imports }}
+	{{ .String }}
+	{{- end }}
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = codes.OK
+var _ = status.Errorf
+
+{{ range $sIdx, $service := .Services }}
+
+type http{{ $service.Name }}Client struct {
+	baseURL	string
+	client	*http.Client
+	cdc		codec.ClientCodec
+}
+
+// New{{ $service.Name }}HTTPClient returns {{ $service.Name }}Client calling the {{ $service.Name }}Router served at baseURL.
+// Call options are ignored. Server-streaming methods ask for newline-delimited JSON,
+// client-streaming and bidirectional streaming ones are not supported over HTTP.
+func New{{ $service.Name }}HTTPClient(baseURL string, httpClient *http.Client, cdc codec.ClientCodec) {{ $service.Name }}Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &http{{ $service.Name }}Client{
+		baseURL:	baseURL,
+		client:		httpClient,
+		cdc:		cdc,
+	}
+}
+
+{{ range $hIdx, $handler := $service.Methods }}
+{{ if $handler.ClientStreaming }}
+func (c *http{{ $service.Name }}Client) {{ $handler.Name }}(ctx context.Context, opts ...grpc.CallOption) ({{ $service.Name }}_{{ $handler.Name }}Client, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method {{ $handler.Name }} is not supported over HTTP")
+}
+{{ else if $handler.ServerStreaming }}
+func (c *http{{ $service.Name }}Client) {{ $handler.Name }}(ctx context.Context, in *{{ $handler.Arg }}, opts ...grpc.CallOption) ({{ $service.Name }}_{{ $handler.Name }}Client, error) {
+	stream, err := codec.InvokeStream(ctx, c.client, c.cdc, c.baseURL, "/{{ lower $service.Name }}/{{ lower $handler.Name }}", in)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http{{ $service.Name }}{{ $handler.Name }}Client{stream}, nil
+}
+
+type http{{ $service.Name }}{{ $handler.Name }}Client struct {
+	grpc.ClientStream
+}
+
+func (x *http{{ $service.Name }}{{ $handler.Name }}Client) Recv() (*{{ $handler.Response }}, error) {
+	m := new({{ $handler.Response }})
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{ else }}
+func (c *http{{ $service.Name }}Client) {{ $handler.Name }}(ctx context.Context, in *{{ $handler.Arg }}, opts ...grpc.CallOption) (*{{ $handler.Response }}, error) {
+	out := new({{ $handler.Response }})
+	if err := codec.Invoke(ctx, c.client, c.cdc, c.baseURL, "/{{ lower $service.Name }}/{{ lower $handler.Name }}", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+{{ end }}
+{{ end }}
+
+{{ end }}
+`))
+)
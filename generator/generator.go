@@ -2,6 +2,7 @@ package generator
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"go/format"
@@ -13,7 +14,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/protoc-gen-go/plugin"
 	"github.com/lazada/protoc-gen-go-http/descriptor"
-	options "google.golang.org/genproto/googleapis/api/annotations"
+	"github.com/lazada/protoc-gen-go-http/openapi"
 )
 
 var (
@@ -23,21 +24,138 @@ var (
 const (
 	minimal = iota
 	router
+	client
+	mock
 )
 
 type generator struct {
 	reg               *descriptor.Registry
 	useRequestContext bool
 	withRouter        bool
+	withClient        bool
+	withMock          bool
+	withSwagger       bool
+	webSocket         bool
+	openAPI           string
+	jsonSchema        bool
+	typeScript        bool
+	php               bool
+	origName          bool
+	methodNaming      string
+}
+
+type option func(*generator)
+
+// WithWebSocket sets whether bidirectional streaming methods are served over WebSocket.
+// They are skipped otherwise.
+func WithWebSocket(enabled bool) option {
+	return func(g *generator) {
+		g.webSocket = enabled
+	}
+}
+
+// Modes of OpenAPI 3.1 output.
+const (
+	// OpenAPIFile emits a document per proto file, named <file>.openapi.json.
+	OpenAPIFile = "file"
+	// OpenAPIPackage emits a document per proto package, merging its files,
+	// named <package>.openapi.json and placed next to the first file of the package.
+	OpenAPIPackage = "package"
+)
+
+// WithOpenAPI sets how OpenAPI 3.1 documents are emitted, OpenAPIFile or OpenAPIPackage.
+// None are emitted if mode is empty.
+func WithOpenAPI(mode string) option {
+	return func(g *generator) {
+		g.openAPI = mode
+	}
+}
+
+// WithJSONSchema sets whether a JSON Schema document is emitted for every message
+// defined in the target files.
+func WithJSONSchema(enabled bool) option {
+	return func(g *generator) {
+		g.jsonSchema = enabled
+	}
+}
+
+// WithTypeScript sets whether a TypeScript client is generated for every file with services.
+func WithTypeScript(enabled bool) option {
+	return func(g *generator) {
+		g.typeScript = enabled
+	}
+}
+
+// WithPHP sets whether a PHP client is generated for every file, with classes of its messages and enums.
+func WithPHP(enabled bool) option {
+	return func(g *generator) {
+		g.php = enabled
+	}
+}
+
+// WithOrigName sets whether API descriptions and clients name fields as in the .proto file,
+// for codecs created with codec.WithOrigName. Fields are named by their JSON names otherwise.
+func WithOrigName(enabled bool) option {
+	return func(g *generator) {
+		g.origName = enabled
+	}
+}
+
+// Naming schemes of the methods in JSON-RPC requests, see runtime.MethodNaming.
+const (
+	// MethodNamingPath names methods by their lowercased routes, e.g. "/example/getperson".
+	MethodNamingPath = "path"
+	// MethodNamingService names methods after their services, e.g. "Example.GetPerson".
+	MethodNamingService = "service"
+	// MethodNamingSnake names methods after their services in snake case, e.g. "example.get_person".
+	MethodNamingSnake = "snake"
+	// MethodNamingPackage names methods by their fully qualified names, e.g. "example.Example.GetPerson".
+	MethodNamingPackage = "package"
+)
+
+// methodNamings are the runtime variables of the naming schemes.
+var methodNamings = map[string]string{
+	MethodNamingPath:    "PathNaming",
+	MethodNamingService: "ServiceNaming",
+	MethodNamingSnake:   "SnakeNaming",
+	MethodNamingPackage: "PackageNaming",
+}
+
+// ValidateMethodNaming returns an error if scheme is not one of the naming schemes.
+func ValidateMethodNaming(scheme string) error {
+	if _, ok := methodNamings[scheme]; !ok {
+		return fmt.Errorf("unknown method naming scheme %q", scheme)
+	}
+	return nil
+}
+
+// WithMethodNaming sets the default naming scheme of the methods in JSON-RPC requests,
+// MethodNamingPath by default. Routers accept the names of MethodNamingPath regardless.
+// Unknown schemes, which ValidateMethodNaming reports, are ignored.
+func WithMethodNaming(scheme string) option {
+	return func(g *generator) {
+		if naming, ok := methodNamings[scheme]; ok {
+			g.methodNaming = naming
+		}
+	}
 }
 
 // New returns a new generator which generates plugin files.
-func New(reg *descriptor.Registry, useRequestContext bool) Generator {
-	return &generator{
+func New(reg *descriptor.Registry, useRequestContext bool, opts ...option) Generator {
+	g := &generator{
 		reg:               reg,
 		useRequestContext: useRequestContext,
 		withRouter:        true,
+		withClient:        true,
+		withMock:          true,
+		withSwagger:       true,
+		methodNaming:      methodNamings[MethodNamingPath],
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+
+	return g
 }
 
 func (g *generator) Generate(targets []*descriptor.File) (files []*plugin_go.CodeGeneratorResponse_File, err error) {
@@ -55,6 +173,62 @@ func (g *generator) Generate(targets []*descriptor.File) (files []*plugin_go.Cod
 		files = append(files, routerFiles...)
 	}
 
+	if g.withClient {
+		clientFiles, err := g.buildFiles(targets, client)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, clientFiles...)
+	}
+
+	if g.withMock {
+		mockFiles, err := g.buildFiles(targets, mock)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, mockFiles...)
+	}
+
+	if g.withSwagger {
+		swaggerFiles, err := g.buildSwaggerFiles(targets)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, swaggerFiles...)
+	}
+
+	if g.openAPI != "" {
+		openAPIFiles, err := g.buildOpenAPIFiles(targets)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, openAPIFiles...)
+	}
+
+	if g.jsonSchema {
+		schemaFiles, err := g.buildJSONSchemaFiles(targets)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, schemaFiles...)
+	}
+
+	if g.typeScript {
+		tsFiles, err := g.buildTypeScriptFiles(targets)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, tsFiles...)
+	}
+
+	if g.php {
+		phpFiles, err := g.buildPHPFiles(targets)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, phpFiles...)
+	}
+
 	return files, nil
 }
 
@@ -69,6 +243,10 @@ func (g *generator) buildFiles(targets []*descriptor.File, mode int) (files []*p
 		fromTemplate, fileName = MinimalTemplate, "%s.pb.http.go"
 	case router:
 		fromTemplate, fileName = RouterTemplate, "%s.pb.http.router.go"
+	case client:
+		fromTemplate, fileName = ClientTemplate, "%s.pb.http.client.go"
+	case mock:
+		fromTemplate, fileName = MockTemplate, "%s.pb.http.mock.go"
 	}
 
 	for _, file := range targets {
@@ -104,30 +282,191 @@ func (g *generator) buildFiles(targets []*descriptor.File, mode int) (files []*p
 	return files, nil
 }
 
+// buildSwaggerFiles returns the Swagger documents of the targets, leaving out the files
+// whose services cannot be described as the router does.
+func (g *generator) buildSwaggerFiles(targets []*descriptor.File) (files []*plugin_go.CodeGeneratorResponse_File, err error) {
+	for _, file := range targets {
+		if len(file.Services) == 0 {
+			continue
+		}
+
+		doc, err := openapi.Swagger(g.reg, file, openapi.WithOrigName(g.origName))
+		if err != nil {
+			log.Printf("warning: %s: no Swagger document is generated: %v", file.GetName(), err)
+			continue
+		}
+
+		var (
+			name = file.GetName()
+			base = strings.TrimSuffix(name, filepath.Ext(name))
+		)
+		files = append(files, &plugin_go.CodeGeneratorResponse_File{
+			Name:    proto.String(base + ".swagger.json"),
+			Content: proto.String(string(doc) + "\n"),
+		})
+	}
+
+	return files, nil
+}
+
+func (g *generator) buildOpenAPIFiles(targets []*descriptor.File) (files []*plugin_go.CodeGeneratorResponse_File, err error) {
+	var (
+		groups [][]*descriptor.File
+		names  []string
+	)
+	switch g.openAPI {
+	case OpenAPIFile:
+		for _, file := range targets {
+			name := file.GetName()
+			groups = append(groups, []*descriptor.File{file})
+			names = append(names, strings.TrimSuffix(name, filepath.Ext(name))+".openapi.json")
+		}
+	case OpenAPIPackage:
+		index := make(map[string]int)
+		for _, file := range targets {
+			i, ok := index[file.GetPackage()]
+			if !ok {
+				i = len(groups)
+				index[file.GetPackage()] = i
+				name := "openapi.json"
+				if file.GetPackage() != "" {
+					name = file.GetPackage() + ".openapi.json"
+				}
+				groups = append(groups, nil)
+				names = append(names, filepath.Join(filepath.Dir(file.GetName()), name))
+			}
+			groups[i] = append(groups[i], file)
+		}
+	default:
+		return nil, fmt.Errorf("unknown OpenAPI output mode %q", g.openAPI)
+	}
+
+	for i, group := range groups {
+		var (
+			withServices []*descriptor.File
+			title        []string
+		)
+		for _, file := range group {
+			if len(file.Services) > 0 {
+				withServices = append(withServices, file)
+				title = append(title, file.GetName())
+			}
+		}
+		if len(withServices) == 0 {
+			continue
+		}
+		if g.openAPI == OpenAPIPackage && withServices[0].GetPackage() != "" {
+			title = []string{withServices[0].GetPackage()}
+		}
+
+		doc, err := openapi.OpenAPI(g.reg, withServices, strings.Join(title, ", "), openapi.WithOrigName(g.origName))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &plugin_go.CodeGeneratorResponse_File{
+			Name:    proto.String(names[i]),
+			Content: proto.String(string(doc) + "\n"),
+		})
+	}
+
+	return files, nil
+}
+
+func (g *generator) buildJSONSchemaFiles(targets []*descriptor.File) (files []*plugin_go.CodeGeneratorResponse_File, err error) {
+	for _, file := range targets {
+		for _, m := range file.Messages {
+			if m.GetOptions().GetMapEntry() {
+				continue
+			}
+
+			doc, err := openapi.JSONSchema(g.reg, m, openapi.WithOrigName(g.origName))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", file.GetName(), err)
+			}
+			files = append(files, &plugin_go.CodeGeneratorResponse_File{
+				Name:    proto.String(filepath.Join(filepath.Dir(file.GetName()), openapi.JSONSchemaName(m))),
+				Content: proto.String(string(doc) + "\n"),
+			})
+		}
+	}
+
+	return files, nil
+}
+
 func (g *generator) generateFrom(file *descriptor.File, t *template.Template) (string, error) {
-	pkgSeen := make(map[string]bool)
-	var imports []descriptor.GoPackage
+	var (
+		pkgSeen, allPkgSeen          = make(map[string]bool), make(map[string]bool)
+		handlerPkgSeen, unaryPkgSeen = make(map[string]bool), make(map[string]bool)
+		imports, allImports          []descriptor.GoPackage
+		handlerImports, unaryImports []descriptor.GoPackage
+	)
 	tFileInfo := &templateFileInfo{
-		Package: file.GoPkg.Name,
+		Package:   file.GoPkg.Name,
+		WebSocket: g.webSocket,
+	}
+	if len(file.Services) == 0 {
+		return "", errNoTargetService
+	}
+
+	tFileInfo.MethodNaming = g.methodNaming
+
+	// only the router serves the Swagger document, which is left out if the services cannot be described
+	if t == RouterTemplate {
+		swagger, err := g.compactSwagger(file)
+		if err != nil {
+			log.Printf("warning: %s: the router serves no Swagger document: %v", file.GetName(), err)
+		}
+		tFileInfo.Swagger = swagger
 	}
 
 	for _, svc := range file.Services {
 		tService := &templateService{Name: svc.GetName()}
 		tFileInfo.Services = append(tFileInfo.Services, tService)
+		routes := make(map[string]string)
 
 		for _, m := range svc.Methods {
-			if m.GetServerStreaming() || m.GetClientStreaming() {
+			tHandler := &templateHandler{
+				Name:            m.GetName(),
+				FullMethod:      fullMethod(m),
+				Arg:             m.RequestType.GoType(file.GoPkg.Path),
+				Response:        m.ResponseType.GoType(file.GoPkg.Path),
+				ServerStreaming: m.GetServerStreaming(),
+				ClientStreaming: m.GetClientStreaming(),
+			}
+			tService.Methods = append(tService.Methods, tHandler)
+			allImports = g.markSeen(file, m.RequestType, allPkgSeen, allImports)
+			allImports = g.markSeen(file, m.ResponseType, allPkgSeen, allImports)
+			// only the message types of the client methods which are not stubs need importing
+			if !tHandler.ClientStreaming {
+				imports = g.markSeen(file, m.RequestType, pkgSeen, imports)
+				imports = g.markSeen(file, m.ResponseType, pkgSeen, imports)
+			}
+			if tHandler.Bidi() && !g.webSocket {
 				continue
 			}
+			// handlers of unary methods only refer to the request type, the response is inferred
+			handlerImports = g.markSeen(file, m.RequestType, handlerPkgSeen, handlerImports)
+			if tHandler.Unary() {
+				unaryImports = g.markSeen(file, m.RequestType, unaryPkgSeen, unaryImports)
+			} else {
+				handlerImports = g.markSeen(file, m.ResponseType, handlerPkgSeen, handlerImports)
+			}
 
-			tService.Handlers = append(tService.Handlers, &templateHandler{
-				Name: m.GetName(),
-				Arg:  m.GetInputType()[1:],
-			})
+			for _, b := range m.Bindings {
+				if other, ok := routes[b.Route()]; ok {
+					return "", fmt.Errorf("%s.%s: route %q is already bound to %s", svc.GetName(), m.GetName(), b.Route(), other)
+				}
+				routes[b.Route()] = m.GetName()
 
-			g.markSeen(file, m, pkgSeen, imports)
+				tBinding := newTemplateBinding(b)
+				tHandler.Bindings = append(tHandler.Bindings, tBinding)
+				tHandler.ResponseBodies = tHandler.ResponseBodies || tBinding.ResponseBody != ""
+			}
+			tService.Handlers = append(tService.Handlers, tHandler)
 		}
 	}
+	tFileInfo.Imports, tFileInfo.AllImports = imports, allImports
+	tFileInfo.HandlerImports, tFileInfo.UnaryImports = handlerImports, unaryImports
 
 	buf := bytes.NewBuffer([]byte{})
 	t.Execute(buf, tFileInfo)
@@ -135,12 +474,73 @@ func (g *generator) generateFrom(file *descriptor.File, t *template.Template) (s
 	return buf.String(), nil
 }
 
-func (g *generator) markSeen(file *descriptor.File, m *descriptor.Method, pkgSeen map[string]bool, imports []descriptor.GoPackage) {
-	pkg := m.RequestType.File.GoPkg
-	if m.Options == nil || !proto.HasExtension(m.Options, options.E_Http) ||
-		pkg == file.GoPkg || pkgSeen[pkg.Path] {
-		return
+// compactSwagger returns the compact Swagger document of the services of the file.
+func (g *generator) compactSwagger(file *descriptor.File) (string, error) {
+	doc, err := openapi.Swagger(g.reg, file, openapi.WithOrigName(g.origName))
+	if err != nil {
+		return "", err
+	}
+	compact := bytes.NewBuffer(nil)
+	if err := json.Compact(compact, doc); err != nil {
+		return "", err
+	}
+	return compact.String(), nil
+}
+
+func newTemplateBinding(b *descriptor.Binding) *templateBinding {
+	tBinding := &templateBinding{
+		Route: b.Route(),
+		Index: b.Index,
+	}
+	for _, p := range b.PathParams {
+		tParam := &templateParam{Name: p.FieldPath.String()}
+		tBinding.Filter = append(tBinding.Filter, tParam.Name)
+		// nested fields are instantiated by runtime.PopulateFieldFromPath
+		if conv, ok := p.ConvertFuncExpr(); ok && len(p.FieldPath) == 1 {
+			tParam.Assign, tParam.Convert = p.FieldPath.RHS("arg"), conv
+		}
+		tBinding.PathParams = append(tBinding.PathParams, tParam)
+	}
+
+	switch {
+	case b.Body == nil:
+		tBinding.Query = true
+	case len(b.Body.FieldPath) == 0:
+		tBinding.Body = "arg"
+	default:
+		// the body is a top-level field, which may be repeated, a map or a scalar
+		tBinding.Body = fieldExpr("arg", b.Body.FieldPath[0].Target)
+		tBinding.Query = true
+		tBinding.Filter = append(tBinding.Filter, b.Body.FieldPath.String())
+	}
+
+	if b.ResponseBody != nil {
+		tBinding.ResponseBody = fieldExpr("grpcResp", b.ResponseBody.FieldPath[0].Target)
+	}
+
+	return tBinding
+}
+
+// fieldExpr returns the codec.Field expression of the field of the message msg,
+// which codecs encode as the message encodes the field.
+func fieldExpr(msg string, f *descriptor.Field) string {
+	return fmt.Sprintf("codec.Field{Message: %s, Name: %q, JSONName: %q}", msg, f.GetName(), openapi.FieldName(f, false))
+}
+
+// fullMethod returns the name gRPC calls the method by, e.g. "/example.Example/GetPerson".
+func fullMethod(m *descriptor.Method) string {
+	svc := m.Service.GetName()
+	if pkg := m.Service.File.GetPackage(); pkg != "" {
+		svc = pkg + "." + svc
+	}
+	return fmt.Sprintf("/%s/%s", svc, m.GetName())
+}
+
+func (g *generator) markSeen(file *descriptor.File, msg *descriptor.Message, pkgSeen map[string]bool, imports []descriptor.GoPackage) []descriptor.GoPackage {
+	pkg := msg.File.GoPkg
+	if pkg == file.GoPkg || pkgSeen[pkg.Path] {
+		return imports
 	}
 	pkgSeen[pkg.Path] = true
-	imports = append(imports, pkg)
+	return append(imports, pkg)
 }
@@ -0,0 +1,553 @@
+package generator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	gendesc "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+	example "github.com/lazada/protoc-gen-go-http/example/pb"
+)
+
+var update = flag.Bool("update", false, "update the files generated for the example")
+
+// exampleDir holds the files generated for example.proto, which the generator output is compared to.
+const exampleDir = "../example/pb"
+
+// loadFiles loads the files into a new registry and returns the last of them as the target.
+func loadFiles(t *testing.T, files ...*gendesc.FileDescriptorProto) (*descriptor.Registry, *descriptor.File) {
+	target := files[len(files)-1].GetName()
+	reg := descriptor.NewRegistry()
+	if err := reg.Load(&plugin_go.CodeGeneratorRequest{
+		FileToGenerate: []string{target},
+		ProtoFile:      files,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	file, err := reg.LookupFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg, file
+}
+
+// importedPaths returns the paths of the packages the Go source imports.
+func importedPaths(t *testing.T, src string) map[string]bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("%v: %s", err, src)
+	}
+	paths := make(map[string]bool)
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		paths[path] = true
+	}
+	return paths
+}
+
+func TestGenerateFromImports(t *testing.T) {
+	other := &gendesc.FileDescriptorProto{
+		Name:    proto.String("other/other.proto"),
+		Package: proto.String("other"),
+		Syntax:  proto.String("proto3"),
+		Options: &gendesc.FileOptions{GoPackage: proto.String("example.com/other")},
+		MessageType: []*gendesc.DescriptorProto{
+			{Name: proto.String("Message")},
+		},
+	}
+	const otherPath = "example.com/other"
+
+	for _, tc := range []struct {
+		name   string
+		method *gendesc.MethodDescriptorProto
+		// router, minimal and client tell whether the templates import the other package
+		router, minimal, client bool
+	}{
+		{
+			name: "unary, imported request",
+			method: &gendesc.MethodDescriptorProto{
+				InputType:  proto.String(".other.Message"),
+				OutputType: proto.String(".svc.Local"),
+			},
+			router:  true,
+			minimal: true,
+			client:  true,
+		},
+		{
+			name: "unary, imported response",
+			method: &gendesc.MethodDescriptorProto{
+				InputType:  proto.String(".svc.Local"),
+				OutputType: proto.String(".other.Message"),
+			},
+			client: true,
+		},
+		{
+			name: "server streaming, imported response",
+			method: &gendesc.MethodDescriptorProto{
+				InputType:       proto.String(".svc.Local"),
+				OutputType:      proto.String(".other.Message"),
+				ServerStreaming: proto.Bool(true),
+			},
+			router: true,
+			client: true,
+		},
+		{
+			name: "client streaming, imported request",
+			method: &gendesc.MethodDescriptorProto{
+				InputType:       proto.String(".other.Message"),
+				OutputType:      proto.String(".svc.Local"),
+				ClientStreaming: proto.Bool(true),
+			},
+			router: true,
+		},
+		{
+			name: "bidi streaming without WebSocket",
+			method: &gendesc.MethodDescriptorProto{
+				InputType:       proto.String(".other.Message"),
+				OutputType:      proto.String(".other.Message"),
+				ClientStreaming: proto.Bool(true),
+				ServerStreaming: proto.Bool(true),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.method.Name = proto.String("Call")
+			svc := &gendesc.FileDescriptorProto{
+				Name:       proto.String("svc.proto"),
+				Package:    proto.String("svc"),
+				Syntax:     proto.String("proto3"),
+				Dependency: []string{"other/other.proto"},
+				MessageType: []*gendesc.DescriptorProto{
+					{Name: proto.String("Local")},
+				},
+				Service: []*gendesc.ServiceDescriptorProto{{
+					Name:   proto.String("Service"),
+					Method: []*gendesc.MethodDescriptorProto{tc.method},
+				}},
+			}
+			reg, file := loadFiles(t, other, svc)
+			g := New(reg, false).(*generator)
+
+			for _, tmpl := range []struct {
+				name string
+				mode int
+				want bool
+			}{
+				{"router", router, tc.router},
+				{"minimal", minimal, tc.minimal},
+				{"client", client, tc.client},
+			} {
+				files, err := g.buildFiles([]*descriptor.File{file}, tmpl.mode)
+				if err != nil {
+					t.Fatalf("%s: %v", tmpl.name, err)
+				}
+				if got := importedPaths(t, files[0].GetContent())[otherPath]; got != tmpl.want {
+					t.Errorf("%s: want import of %s %v, got %v", tmpl.name, otherPath, tmpl.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFromSwagger(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		field *gendesc.FieldDescriptorProto
+		want  string
+		// described reports whether the file gets a Swagger file
+		described bool
+	}{
+		{
+			name: "described",
+			field: &gendesc.FieldDescriptorProto{
+				Name:   proto.String("name"),
+				Number: proto.Int32(1),
+				Label:  gendesc.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   gendesc.FieldDescriptorProto_TYPE_STRING.Enum(),
+			},
+			want:      "var swaggerJSON = []byte(",
+			described: true,
+		},
+		{
+			name: "groups cannot be described",
+			field: &gendesc.FieldDescriptorProto{
+				Name:     proto.String("group"),
+				Number:   proto.Int32(1),
+				Label:    gendesc.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     gendesc.FieldDescriptorProto_TYPE_GROUP.Enum(),
+				TypeName: proto.String(".svc.Local.Group"),
+			},
+			want: "var swaggerJSON []byte",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &gendesc.FileDescriptorProto{
+				Name:    proto.String("svc.proto"),
+				Package: proto.String("svc"),
+				Syntax:  proto.String("proto2"),
+				MessageType: []*gendesc.DescriptorProto{{
+					Name:       proto.String("Local"),
+					Field:      []*gendesc.FieldDescriptorProto{tc.field},
+					NestedType: []*gendesc.DescriptorProto{{Name: proto.String("Group")}},
+				}},
+				Service: []*gendesc.ServiceDescriptorProto{{
+					Name: proto.String("Service"),
+					Method: []*gendesc.MethodDescriptorProto{{
+						Name:       proto.String("Call"),
+						InputType:  proto.String(".svc.Local"),
+						OutputType: proto.String(".svc.Local"),
+					}},
+				}},
+			}
+			reg, file := loadFiles(t, svc)
+			g := New(reg, false).(*generator)
+
+			files, err := g.buildFiles([]*descriptor.File{file}, router)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := files[0].GetContent(); !strings.Contains(got, tc.want) {
+				t.Fatalf("want the router to contain %q, got %s", tc.want, got)
+			}
+
+			files, err = g.buildFiles([]*descriptor.File{file}, minimal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := files[0].GetContent(); strings.Contains(got, "swaggerJSON") {
+				t.Fatalf("want no Swagger document outside of the router, got %s", got)
+			}
+
+			// files which cannot be described get no Swagger file, the others are generated
+			files, err = g.Generate([]*descriptor.File{file})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var swagger bool
+			for _, f := range files {
+				swagger = swagger || f.GetName() == "svc.swagger.json"
+			}
+			if swagger != tc.described {
+				t.Fatalf("want a Swagger file %v, got %v", tc.described, swagger)
+			}
+		})
+	}
+}
+
+// TestGeneratePHPObjectRequest checks that empty arrays are sent as empty objects
+// for the well-known requests encoded as JSON objects only.
+func TestGeneratePHPObjectRequest(t *testing.T) {
+	wkt := &gendesc.FileDescriptorProto{
+		Name:    proto.String("google/protobuf/wkt.proto"),
+		Package: proto.String("google.protobuf"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*gendesc.DescriptorProto{
+			{Name: proto.String("Empty")},
+			{Name: proto.String("ListValue")},
+		},
+	}
+
+	for _, tc := range []struct {
+		request string
+		want    bool
+	}{
+		{".svc.Local", false},
+		{".google.protobuf.Empty", true},
+		{".google.protobuf.ListValue", false},
+	} {
+		t.Run(tc.request, func(t *testing.T) {
+			svc := &gendesc.FileDescriptorProto{
+				Name:       proto.String("svc.proto"),
+				Package:    proto.String("svc"),
+				Syntax:     proto.String("proto3"),
+				Dependency: []string{"google/protobuf/wkt.proto"},
+				MessageType: []*gendesc.DescriptorProto{
+					{Name: proto.String("Local")},
+				},
+				Service: []*gendesc.ServiceDescriptorProto{{
+					Name: proto.String("Service"),
+					Method: []*gendesc.MethodDescriptorProto{{
+						Name:       proto.String("Call"),
+						InputType:  proto.String(tc.request),
+						OutputType: proto.String(".svc.Local"),
+					}},
+				}},
+			}
+			reg, file := loadFiles(t, wkt, svc)
+			g := New(reg, false, WithPHP(true)).(*generator)
+
+			files, err := g.buildPHPFiles([]*descriptor.File{file})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(files[0].GetContent(), "if ($req === [])"); got != tc.want {
+				t.Fatalf("want the empty array check %v, got %v: %s", tc.want, got, files[0].GetContent())
+			}
+		})
+	}
+}
+
+// mockServer stands in for the Go package protoc-gen-go generates for the service of TestGenerateMockNames.
+const mockServer = `package svc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+type Local struct{}
+
+func (*Local) Reset()         {}
+func (*Local) String() string { return "" }
+func (*Local) ProtoMessage()  {}
+
+type ServiceServer interface {
+	Reset(context.Context, *Local) (*Local, error)
+	Record(Service_RecordServer) error
+	Calls(*Local, Service_CallsServer) error
+}
+
+type Service_RecordServer interface {
+	SendAndClose(*Local) error
+	Recv() (*Local, error)
+	grpc.ServerStream
+}
+
+type Service_CallsServer interface {
+	Send(*Local) error
+	grpc.ServerStream
+}
+
+var _ ServiceServer = (*ServiceMock)(nil)
+`
+
+// exportImporter returns an importer of the packages the files import, reading the export data
+// the go command builds for them.
+func exportImporter(t *testing.T, fset *token.FileSet, files []*ast.File) types.Importer {
+	args := []string{"list", "-export", "-deps", "-f", "{{ .ImportPath }} {{ .Export }}"}
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			args = append(args, path)
+		}
+	}
+	out, err := exec.Command("go", args...).Output()
+	if err != nil {
+		t.Fatalf("go list: %v", err)
+	}
+
+	exports := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			exports[fields[0]] = fields[1]
+		}
+	}
+	return importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		export, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(export)
+	})
+}
+
+// TestGenerateMockNames type-checks the mock of a service whose methods are named like the helpers
+// and the fields of mocks.
+func TestGenerateMockNames(t *testing.T) {
+	svc := &gendesc.FileDescriptorProto{
+		Name:    proto.String("svc.proto"),
+		Package: proto.String("svc"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*gendesc.DescriptorProto{
+			{Name: proto.String("Local")},
+		},
+		Service: []*gendesc.ServiceDescriptorProto{{
+			Name: proto.String("Service"),
+			Method: []*gendesc.MethodDescriptorProto{
+				{Name: proto.String("Reset"), InputType: proto.String(".svc.Local"), OutputType: proto.String(".svc.Local")},
+				{Name: proto.String("Record"), InputType: proto.String(".svc.Local"), OutputType: proto.String(".svc.Local"), ClientStreaming: proto.Bool(true)},
+				{Name: proto.String("Calls"), InputType: proto.String(".svc.Local"), OutputType: proto.String(".svc.Local"), ServerStreaming: proto.Bool(true)},
+			},
+		}},
+	}
+	reg, file := loadFiles(t, svc)
+	g := New(reg, false).(*generator)
+
+	files, err := g.buildFiles([]*descriptor.File{file}, mock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for name, src := range map[string]string{"svc.pb.go": mockServer, files[0].GetName(): files[0].GetContent()} {
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatalf("%v: %s", err, src)
+		}
+		parsed = append(parsed, f)
+	}
+
+	conf := types.Config{Importer: exportImporter(t, fset, parsed)}
+	if _, err := conf.Check("svc", fset, parsed, nil); err != nil {
+		t.Fatalf("want the mock to type-check, got %v: %s", err, files[0].GetContent())
+	}
+}
+
+// loadExample loads example.proto as protoc passes it to the plugin, along with the comments of its methods.
+func loadExample(t *testing.T) (*descriptor.Registry, *descriptor.File) {
+	gz, _ := (&example.Person{}).Descriptor()
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := &gendesc.FileDescriptorProto{}
+	if err := proto.Unmarshal(b, file); err != nil {
+		t.Fatal(err)
+	}
+
+	// the registered descriptor has no source info
+	file.SourceCodeInfo = &gendesc.SourceCodeInfo{Location: []*gendesc.SourceCodeInfo_Location{
+		{Path: []int32{6, 0, 2, 0}, LeadingComments: proto.String(" Simple request/response.\n")},
+		{Path: []int32{6, 0, 2, 1}, LeadingComments: proto.String(" Server streaming (ignored).\n")},
+	}}
+	return loadFiles(t, file)
+}
+
+// TestGenerateExample compares the files generated for example.proto to the ones in exampleDir.
+// Run it with -update to regenerate them.
+func TestGenerateExample(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		opts  []option
+		files []string
+	}{
+		{
+			name:  "OpenAPI",
+			opts:  []option{WithOpenAPI(OpenAPIFile)},
+			files: []string{"example.openapi.json"},
+		},
+		{
+			name:  "JSON Schema",
+			opts:  []option{WithJSONSchema(true)},
+			files: []string{"Query.schema.json", "Person.schema.json"},
+		},
+		{
+			name:  "TypeScript",
+			opts:  []option{WithTypeScript(true)},
+			files: []string{"example.pb.http.ts"},
+		},
+		{
+			name:  "PHP",
+			opts:  []option{WithPHP(true)},
+			files: []string{"example.pb.http.php"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reg, file := loadExample(t)
+			files, err := New(reg, false, tc.opts...).Generate([]*descriptor.File{file})
+			if err != nil {
+				t.Fatal(err)
+			}
+			generated := make(map[string]string)
+			for _, f := range files {
+				generated[f.GetName()] = f.GetContent()
+			}
+
+			for _, name := range tc.files {
+				got, ok := generated[name]
+				if !ok {
+					t.Fatalf("want %s to be generated", name)
+				}
+				path := filepath.Join(exampleDir, name)
+				if *update {
+					if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+
+				want, err := ioutil.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != string(want) {
+					t.Errorf("%s differs from the generated file, run go test -update to regenerate it:\n%s", path, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldExpr(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		field *gendesc.FieldDescriptorProto
+		want  string
+	}{
+		{
+			name:  "JSON name set by protoc",
+			field: &gendesc.FieldDescriptorProto{Name: proto.String("page_items"), JsonName: proto.String("pageItems")},
+			want:  `codec.Field{Message: arg, Name: "page_items", JSONName: "pageItems"}`,
+		},
+		{
+			name:  "JSON name computed",
+			field: &gendesc.FieldDescriptorProto{Name: proto.String("page_items")},
+			want:  `codec.Field{Message: arg, Name: "page_items", JSONName: "pageItems"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fieldExpr("arg", &descriptor.Field{FieldDescriptorProto: tc.field}); got != tc.want {
+				t.Fatalf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMethodNaming(t *testing.T) {
+	for _, tc := range []struct {
+		scheme string
+		want   string
+		// err is true if the scheme is unknown, the default is kept then
+		err bool
+	}{
+		{scheme: MethodNamingPath, want: "PathNaming"},
+		{scheme: MethodNamingService, want: "ServiceNaming"},
+		{scheme: MethodNamingSnake, want: "SnakeNaming"},
+		{scheme: MethodNamingPackage, want: "PackageNaming"},
+		{scheme: "camel", want: "PathNaming", err: true},
+		{scheme: "", want: "PathNaming", err: true},
+	} {
+		t.Run(tc.scheme, func(t *testing.T) {
+			if err := ValidateMethodNaming(tc.scheme); (err != nil) != tc.err {
+				t.Fatalf("want an error: %v, got %v", tc.err, err)
+			}
+			g := New(descriptor.NewRegistry(), false, WithMethodNaming(tc.scheme)).(*generator)
+			if g.methodNaming != tc.want {
+				t.Fatalf("want runtime.%s, got runtime.%s", tc.want, g.methodNaming)
+			}
+		})
+	}
+}
@@ -15,6 +15,9 @@ import (
 	"net/http"
 
 	"github.com/lazada/protoc-gen-go-http/codec"
+	{{- range .UnaryImports }}
+	{{ .String }}
+	{{- end }}
 )
 
 {{ range $sIdx, $service := .Services }}
@@ -31,7 +34,7 @@ func NewHTTP{{ $service.Name }}Server(srv {{ $service.Name }}Server, cdc codec.C
 	}
 }
 
-{{ range $hIdx, $handler := $service.Handlers }}
+{{ range $hIdx, $handler := $service.Handlers }}{{ if $handler.Unary }}
 func (s *HTTP{{ $service.Name }}Server) {{ $handler.Name }}(w http.ResponseWriter, r *http.Request) {
     defer r.Body.Close()
 	arg := {{ $handler.Arg }}{}
@@ -47,7 +50,7 @@ func (s *HTTP{{ $service.Name }}Server) {{ $handler.Name }}(w http.ResponseWrite
 	}
 	s.cdc.WriteResponse(w, grpcResp)
 }
-{{ end }}
+{{ end }}{{ end }}
 
 {{ end }}
 
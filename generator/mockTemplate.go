@@ -0,0 +1,271 @@
+package generator
+
+import (
+	"text/template"
+)
+
+var (
+	MockTemplate = template.Must(template.New(`file`).Parse(`
+package {{ .Package }}
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	{{- range .AllImports }}
+	{{ .String }}
+	{{- end }}
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = io.EOF
+var _ context.Context
+
+{{ range $sIdx, $service := .Services }}
+
+// {{ $service.Name }}MockCall is a call received by {{ $service.Name }}Mock.
+type {{ $service.Name }}MockCall struct {
+	// Method is the name of the called method as in the service definition.
+	Method string
+	// Metadata is the incoming metadata of the call.
+	Metadata metadata.MD
+	// Requests are the request messages received so far, a single one for unary
+	// and server-streaming methods.
+	Requests []proto.Message
+}
+
+// {{ $service.Name }}Mock is a {{ $service.Name }}Server answering with canned or scripted responses,
+// which records the calls it receives. Methods without responses return an Unimplemented error.
+// Serve it with New{{ $service.Name }}Router to test consumers against the real codec and routes.
+type {{ $service.Name }}Mock struct {
+	// state is a field of its own, so that it cannot collide with the methods of the service
+	state struct {
+		mu		sync.Mutex
+		calls	[]*{{ $service.Name }}MockCall
+		{{ range $hIdx, $handler := $service.Methods }}
+		{{- if $handler.ClientStreaming }}
+		on{{ $handler.Name }}	func(stream {{ $service.Name }}_{{ $handler.Name }}Server) error
+		{{- else if $handler.ServerStreaming }}
+		on{{ $handler.Name }}	func(in *{{ $handler.Arg }}, stream {{ $service.Name }}_{{ $handler.Name }}Server) error
+		{{- else }}
+		on{{ $handler.Name }}	func(ctx context.Context, in *{{ $handler.Arg }}) (*{{ $handler.Response }}, error)
+		{{- end }}
+		{{- end }}
+	}
+}
+
+// New{{ $service.Name }}Mock returns a {{ $service.Name }}Mock without responses.
+func New{{ $service.Name }}Mock() *{{ $service.Name }}Mock {
+	return &{{ $service.Name }}Mock{}
+}
+
+// MockCalls returns the calls received so far, in order.
+func (m *{{ $service.Name }}Mock) MockCalls() []{{ $service.Name }}MockCall {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	calls := make([]{{ $service.Name }}MockCall, len(m.state.calls))
+	for i, call := range m.state.calls {
+		calls[i] = *call
+		calls[i].Requests = append([]proto.Message(nil), call.Requests...)
+	}
+	return calls
+}
+
+// MockCallsTo returns the calls of the method received so far, in order.
+func (m *{{ $service.Name }}Mock) MockCallsTo(method string) []{{ $service.Name }}MockCall {
+	var calls []{{ $service.Name }}MockCall
+	for _, call := range m.MockCalls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// MockReset forgets the calls received so far. Responses are kept.
+func (m *{{ $service.Name }}Mock) MockReset() {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.calls = nil
+}
+
+func (m *{{ $service.Name }}Mock) record(ctx context.Context, method string, reqs ...proto.Message) *{{ $service.Name }}MockCall {
+	md, _ := metadata.FromIncomingContext(ctx)
+	call := &{{ $service.Name }}MockCall{
+		Method:		method,
+		Metadata:	md,
+		Requests:	reqs,
+	}
+
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.calls = append(m.state.calls, call)
+	return call
+}
+
+func (m *{{ $service.Name }}Mock) recordRequest(call *{{ $service.Name }}MockCall, req proto.Message) {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	call.Requests = append(call.Requests, req)
+}
+
+{{ range $hIdx, $handler := $service.Methods }}
+{{ if $handler.ClientStreaming }}
+// On{{ $handler.Name }} scripts {{ $handler.Name }} with f, which serves every call.
+// The messages f receives from the stream are recorded.
+func (m *{{ $service.Name }}Mock) On{{ $handler.Name }}(f func(stream {{ $service.Name }}_{{ $handler.Name }}Server) error) *{{ $service.Name }}Mock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.on{{ $handler.Name }} = f
+	return m
+}
+{{ if $handler.ServerStreaming }}
+// Return{{ $handler.Name }} makes {{ $handler.Name }} receive all the request messages of every call,
+// then send resps and return err.
+func (m *{{ $service.Name }}Mock) Return{{ $handler.Name }}(resps []*{{ $handler.Response }}, err error) *{{ $service.Name }}Mock {
+	return m.On{{ $handler.Name }}(func(stream {{ $service.Name }}_{{ $handler.Name }}Server) error {
+		for {
+			_, recvErr := stream.Recv()
+			if recvErr == io.EOF {
+				break
+			}
+			if recvErr != nil {
+				return recvErr
+			}
+		}
+		for _, resp := range resps {
+			if sendErr := stream.Send(resp); sendErr != nil {
+				return sendErr
+			}
+		}
+		return err
+	})
+}
+{{ else }}
+// Return{{ $handler.Name }} makes {{ $handler.Name }} receive all the request messages of every call,
+// then answer with resp and err.
+func (m *{{ $service.Name }}Mock) Return{{ $handler.Name }}(resp *{{ $handler.Response }}, err error) *{{ $service.Name }}Mock {
+	return m.On{{ $handler.Name }}(func(stream {{ $service.Name }}_{{ $handler.Name }}Server) error {
+		for {
+			_, recvErr := stream.Recv()
+			if recvErr == io.EOF {
+				break
+			}
+			if recvErr != nil {
+				return recvErr
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return stream.SendAndClose(resp)
+	})
+}
+{{ end }}
+func (m *{{ $service.Name }}Mock) {{ $handler.Name }}(stream {{ $service.Name }}_{{ $handler.Name }}Server) error {
+	call := m.record(stream.Context(), "{{ $handler.Name }}")
+
+	m.state.mu.Lock()
+	f := m.state.on{{ $handler.Name }}
+	m.state.mu.Unlock()
+	if f == nil {
+		return status.Errorf(codes.Unimplemented, "method {{ $handler.Name }} is not mocked")
+	}
+
+	return f(&mock{{ $service.Name }}{{ $handler.Name }}Server{stream, m, call})
+}
+
+// mock{{ $service.Name }}{{ $handler.Name }}Server records the request messages received from the stream.
+type mock{{ $service.Name }}{{ $handler.Name }}Server struct {
+	{{ $service.Name }}_{{ $handler.Name }}Server
+	mock	*{{ $service.Name }}Mock
+	call	*{{ $service.Name }}MockCall
+}
+
+func (x *mock{{ $service.Name }}{{ $handler.Name }}Server) Recv() (*{{ $handler.Arg }}, error) {
+	req, err := x.{{ $service.Name }}_{{ $handler.Name }}Server.Recv()
+	if err != nil {
+		return nil, err
+	}
+	x.mock.recordRequest(x.call, req)
+	return req, nil
+}
+{{ else if $handler.ServerStreaming }}
+// On{{ $handler.Name }} scripts {{ $handler.Name }} with f, which serves every call after it is recorded.
+func (m *{{ $service.Name }}Mock) On{{ $handler.Name }}(f func(in *{{ $handler.Arg }}, stream {{ $service.Name }}_{{ $handler.Name }}Server) error) *{{ $service.Name }}Mock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.on{{ $handler.Name }} = f
+	return m
+}
+
+// Return{{ $handler.Name }} makes {{ $handler.Name }} send resps and then return err on every call.
+func (m *{{ $service.Name }}Mock) Return{{ $handler.Name }}(resps []*{{ $handler.Response }}, err error) *{{ $service.Name }}Mock {
+	return m.On{{ $handler.Name }}(func(in *{{ $handler.Arg }}, stream {{ $service.Name }}_{{ $handler.Name }}Server) error {
+		for _, resp := range resps {
+			if sendErr := stream.Send(resp); sendErr != nil {
+				return sendErr
+			}
+		}
+		return err
+	})
+}
+
+func (m *{{ $service.Name }}Mock) {{ $handler.Name }}(in *{{ $handler.Arg }}, stream {{ $service.Name }}_{{ $handler.Name }}Server) error {
+	m.record(stream.Context(), "{{ $handler.Name }}", in)
+
+	m.state.mu.Lock()
+	f := m.state.on{{ $handler.Name }}
+	m.state.mu.Unlock()
+	if f == nil {
+		return status.Errorf(codes.Unimplemented, "method {{ $handler.Name }} is not mocked")
+	}
+
+	return f(in, stream)
+}
+{{ else }}
+// On{{ $handler.Name }} scripts {{ $handler.Name }} with f, which serves every call after it is recorded.
+func (m *{{ $service.Name }}Mock) On{{ $handler.Name }}(f func(ctx context.Context, in *{{ $handler.Arg }}) (*{{ $handler.Response }}, error)) *{{ $service.Name }}Mock {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.on{{ $handler.Name }} = f
+	return m
+}
+
+// Return{{ $handler.Name }} makes {{ $handler.Name }} answer every call with resp and err.
+func (m *{{ $service.Name }}Mock) Return{{ $handler.Name }}(resp *{{ $handler.Response }}, err error) *{{ $service.Name }}Mock {
+	return m.On{{ $handler.Name }}(func(context.Context, *{{ $handler.Arg }}) (*{{ $handler.Response }}, error) {
+		return resp, err
+	})
+}
+
+func (m *{{ $service.Name }}Mock) {{ $handler.Name }}(ctx context.Context, in *{{ $handler.Arg }}) (*{{ $handler.Response }}, error) {
+	m.record(ctx, "{{ $handler.Name }}", in)
+
+	m.state.mu.Lock()
+	f := m.state.on{{ $handler.Name }}
+	m.state.mu.Unlock()
+	if f == nil {
+		return nil, status.Errorf(codes.Unimplemented, "method {{ $handler.Name }} is not mocked")
+	}
+
+	return f(ctx, in)
+}
+{{ end }}
+{{ end }}
+
+{{ end }}
+`))
+)
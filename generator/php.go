@@ -0,0 +1,243 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+	"github.com/lazada/protoc-gen-go-http/openapi"
+)
+
+func (g *generator) buildPHPFiles(targets []*descriptor.File) (files []*plugin_go.CodeGeneratorResponse_File, err error) {
+	for _, file := range targets {
+		if len(file.Services) == 0 && len(file.Messages) == 0 && len(file.Enums) == 0 {
+			continue
+		}
+
+		tFile, err := g.phpFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file.GetName(), err)
+		}
+		buf := bytes.NewBuffer(nil)
+		if err := PHPTemplate.Execute(buf, tFile); err != nil {
+			return nil, err
+		}
+
+		var (
+			name = file.GetName()
+			base = strings.TrimSuffix(name, filepath.Ext(name))
+		)
+		files = append(files, &plugin_go.CodeGeneratorResponse_File{
+			Name:    proto.String(base + ".pb.http.php"),
+			Content: proto.String(buf.String()),
+		})
+	}
+
+	return files, nil
+}
+
+// phpFile returns the classes of the messages and enums defined in the file and the clients
+// of the unary and server-streaming methods of its services. Messages defined in other files
+// are referred to by the classes generated for those files.
+func (g *generator) phpFile(file *descriptor.File) (*templatePHPFile, error) {
+	tFile := &templatePHPFile{
+		Source:    file.GetName(),
+		Namespace: phpNamespace(file),
+	}
+
+	var msgs []*descriptor.Message
+	for _, m := range file.Messages {
+		if !m.GetOptions().GetMapEntry() && !openapi.IsWellKnownType(m.FQMN()) {
+			msgs = append(msgs, m)
+		}
+	}
+	defs, err := openapi.Definitions(g.reg, msgs, file.Enums, openapi.WithOrigName(g.origName))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range file.Enums {
+		tEnum := &templatePHPEnum{
+			Name:    g.phpClass(e.FQEN(), file),
+			Comment: docComment(defs[strings.TrimPrefix(e.FQEN(), ".")].Description),
+		}
+		for _, v := range e.GetValue() {
+			tEnum.Values = append(tEnum.Values, v.GetName())
+		}
+		tFile.Enums = append(tFile.Enums, tEnum)
+	}
+
+	for _, m := range msgs {
+		s := defs[strings.TrimPrefix(m.FQMN(), ".")]
+		tMessage := &templatePHPMessage{
+			Name:    g.phpClass(m.FQMN(), file),
+			Comment: docComment(s.Description),
+		}
+		for _, f := range m.Fields {
+			tField := &templatePHPField{Name: openapi.FieldName(f, g.origName)}
+			fs := s.Properties[tField.Name]
+			if err := g.phpField(tField, fs, defs, file); err != nil {
+				return nil, err
+			}
+			tField.Comment = strings.Replace(fs.Description, "\n", " ", -1)
+			tMessage.Fields = append(tMessage.Fields, tField)
+		}
+		tFile.Messages = append(tFile.Messages, tMessage)
+	}
+
+	for _, svc := range file.Services {
+		tService := &templatePHPService{Name: svc.GetName()}
+		for _, m := range svc.Methods {
+			if m.GetClientStreaming() {
+				continue
+			}
+			tMethod := &templatePHPMethod{
+				Name:            lowerFirst(m.GetName()),
+				Route:           fmt.Sprintf("/%s/%s", strings.ToLower(svc.GetName()), strings.ToLower(m.GetName())),
+				ServerStreaming: m.GetServerStreaming(),
+			}
+			if !openapi.IsWellKnownType(m.RequestType.FQMN()) {
+				tMethod.Request = g.phpClass(m.RequestType.FQMN(), file)
+			} else {
+				tMethod.ObjectRequest = phpObjectTypes[m.RequestType.FQMN()]
+			}
+			if !openapi.IsWellKnownType(m.ResponseType.FQMN()) {
+				tMethod.Response = g.phpClass(m.ResponseType.FQMN(), file)
+			}
+			tService.Methods = append(tService.Methods, tMethod)
+		}
+		tFile.Services = append(tFile.Services, tService)
+	}
+
+	return tFile, nil
+}
+
+// phpField sets the types of the property of a field with the schema and how it is converted
+// from and to JSON.
+func (g *generator) phpField(tField *templatePHPField, s *openapi.Schema, defs map[string]*openapi.Schema, file *descriptor.File) error {
+	tField.Decode, tField.Encode = "$value", "$this->"+tField.Name
+
+	switch {
+	case s.Ref != "":
+		if def := defs[s.Ref]; def != nil && len(def.Enum) > 0 {
+			tField.Type, tField.DocType = "?string", "string|null"
+			return nil
+		}
+		class := g.phpClass("."+s.Ref, file)
+		tField.Type, tField.DocType = "?"+class, class+"|null"
+		tField.Decode = class + "::fromArray($value)"
+		return nil
+	case s.Type == "array" || s.Type == "object":
+		elem := s.Items
+		if s.Type == "object" {
+			// maps and well-known objects are encoded as JSON objects even if they are empty
+			elem = s.AdditionalProperties
+			tField.Encode = "(object) " + tField.Encode
+		}
+		tField.Type, tField.DocType = "?array", "array|null"
+		if elem == nil {
+			return nil
+		}
+
+		var elemField templatePHPField
+		if err := g.phpField(&elemField, elem, defs, file); err != nil {
+			return err
+		}
+		elemType := strings.TrimSuffix(elemField.DocType, "|null")
+		if elemType == "" {
+			elemType = "mixed"
+		}
+		if s.Type == "object" {
+			tField.DocType = "array<string, " + elemType + ">|null"
+		} else {
+			tField.DocType = elemType + "[]|null"
+		}
+		if elemField.Decode != "$value" {
+			tField.Decode = fmt.Sprintf("array_map([%s::class, 'fromArray'], $value)", strings.TrimPrefix(elemField.Type, "?"))
+		}
+		return nil
+	}
+
+	switch s.Type {
+	case "string", "boolean":
+		t := map[string]string{"string": "string", "boolean": "bool"}[s.Type]
+		tField.Type, tField.DocType = "?"+t, t+"|null"
+	case "integer":
+		tField.Type, tField.DocType = "?int", "int|null"
+	case "number":
+		tField.Type, tField.DocType = "?float", "float|null"
+	default:
+		// any JSON value, e.g. google.protobuf.Value
+		tField.Type, tField.DocType = "", "mixed"
+	}
+	return nil
+}
+
+// phpClass returns the class of a message or an enum: its name with the outer messages,
+// e.g. Owner_Address, qualified by the namespace of its file if it is another one.
+func (g *generator) phpClass(fqn string, file *descriptor.File) string {
+	var (
+		other = file
+		outer string
+	)
+	if m, err := g.reg.LookupMsg("", fqn); err == nil {
+		other = m.File
+	} else if e, err := g.reg.LookupEnum("", fqn); err == nil {
+		other = e.File
+	}
+	if pkg := other.GetPackage(); pkg != "" {
+		outer = pkg + "."
+	}
+
+	name := strings.Replace(strings.TrimPrefix(strings.TrimPrefix(fqn, "."), outer), ".", "_", -1)
+	if phpReserved[strings.ToLower(name)] {
+		name = "PB" + name
+	}
+	if ns := phpNamespace(other); ns != phpNamespace(file) {
+		return `\` + ns + `\` + name
+	}
+	return name
+}
+
+// phpNamespace returns the php_namespace option of the file or, if it is not set,
+// the namespace of the proto package, e.g. Foo\Bar for foo.bar.
+func phpNamespace(file *descriptor.File) string {
+	if ns := file.GetOptions().GetPhpNamespace(); ns != "" {
+		return ns
+	}
+
+	var parts []string
+	for _, part := range strings.Split(file.GetPackage(), ".") {
+		if part != "" {
+			parts = append(parts, strings.ToUpper(part[:1])+part[1:])
+		}
+	}
+	return strings.Join(parts, `\`)
+}
+
+// phpObjectTypes are the well-known types encoded as JSON objects.
+var phpObjectTypes = map[string]bool{
+	".google.protobuf.Empty":  true,
+	".google.protobuf.Struct": true,
+	".google.protobuf.Any":    true,
+}
+
+// phpReserved are the lowercased words which cannot be class names,
+// prefixed with PB as protoc-gen-php does.
+var phpReserved = func() map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(`abstract and array as bool break callable case catch class clone
+		const continue declare default die do echo else elseif empty enddeclare endfor endforeach
+		endif endswitch endwhile eval exit extends false final finally float fn for foreach function
+		global goto if implements include include_once instanceof insteadof int interface isset
+		iterable list match mixed namespace never new null object or parent print private protected
+		public readonly require require_once return self static string switch throw trait true try
+		unset use var void while xor yield`) {
+		words[w] = true
+	}
+	return words
+}()
@@ -0,0 +1,230 @@
+package generator
+
+import (
+	"text/template"
+)
+
+var (
+	PHPTemplate = template.Must(template.New(`file`).Parse(`<?php
+// Code generated by protoc-gen-go-http. DO NOT EDIT.
+// source: {{ .Source }}
+{{ if .Namespace }}
+namespace {{ .Namespace }};
+{{ end }}
+{{- define "comment" }}{{ if . }}/**
+{{ range . }} * {{ . }}
+{{ end }} */
+{{ end }}{{ end }}
+{{- define "objectRequest" }}{{ if .ObjectRequest }}
+        if ($req === []) {
+            // an empty array is an empty message, not a JSON array
+            $req = new \stdClass();
+        }
+{{- end }}{{ end }}
+{{- range .Enums }}
+{{ template "comment" .Comment }}final class {{ .Name }}
+{
+{{- range .Values }}
+    public const {{ . }} = '{{ . }}';
+{{- end }}
+}
+{{ end }}
+{{- range .Messages }}
+{{ template "comment" .Comment }}final class {{ .Name }} implements \JsonSerializable
+{
+{{- range .Fields }}
+    /** @var {{ .DocType }}{{ with .Comment }} {{ . }}{{ end }} */
+    public {{ with .Type }}{{ . }} {{ end }}${{ .Name }} = null;
+{{ end }}
+    public static function fromArray(array $data): self
+    {
+        $m = new self();
+{{- range .Fields }}
+        if (isset($data['{{ .Name }}'])) {
+            $value = $data['{{ .Name }}'];
+            $m->{{ .Name }} = {{ .Decode }};
+        }
+{{- end }}
+        return $m;
+    }
+
+    #[\ReturnTypeWillChange]
+    public function jsonSerialize()
+    {
+        $data = [];
+{{- range .Fields }}
+        if ($this->{{ .Name }} !== null) {
+            $data['{{ .Name }}'] = {{ .Encode }};
+        }
+{{- end }}
+        return (object) $data;
+    }
+}
+{{ end }}
+{{- range $service := .Services }}
+/**
+ * {{ $service.Name }}Exception is an error returned by the {{ $service.Name }}Router, or a failed request.
+ * The code is the JSON-RPC error code or the HTTP status.
+ */
+final class {{ $service.Name }}Exception extends \RuntimeException
+{
+    /** @var array|null */
+    private $status;
+
+    public function __construct(string $message, int $code = 0, ?array $status = null)
+    {
+        parent::__construct($message, $code);
+        $this->status = $status;
+    }
+
+    /**
+     * @return array|null the google.rpc.Status of the error if the router sends it: its gRPC 'code',
+     *     'message' and 'details', an array of the details as typed JSON Any objects
+     */
+    public function getStatus(): ?array
+    {
+        if ($this->status === null) {
+            return null;
+        }
+        return [
+            'code' => $this->status['code'] ?? 0,
+            'message' => $this->status['message'] ?? '',
+            'details' => $this->status['details'] ?? [],
+        ];
+    }
+}
+
+/**
+ * {{ $service.Name }}Client calls the {{ $service.Name }}Router served at a base URL.
+ * Server-streaming methods ask for newline-delimited JSON, client-streaming and
+ * bidirectional streaming ones are not supported.
+ * Errors are thrown as {{ $service.Name }}Exception.
+ */
+final class {{ $service.Name }}Client
+{
+    /** REST posts messages as JSON, for routers serving codec.NewRESTCCodec. */
+    public const REST = 'rest';
+    /** JSON_RPC posts messages in JSON-RPC 2.0 requests, for routers serving codec.NewJsonRPCCodec. */
+    public const JSON_RPC = 'jsonrpc';
+
+    /** @var int */
+    private static $nextID = 0;
+
+    /** @var string */
+    private $baseURL;
+    /** @var string */
+    private $codec;
+    /** @var array */
+    private $headers;
+    /** @var callable */
+    private $transport;
+
+    /**
+     * @param string $codec REST or JSON_RPC
+     * @param array $headers headers sent with every request, e.g. ['Authorization' => 'Bearer token']
+     * @param callable|null $transport posts a request, function (string $url, string $body, array $headers): array
+     *     returning the HTTP status and the response body; curl is used by default
+     */
+    public function __construct(string $baseURL, string $codec = self::REST, array $headers = [], ?callable $transport = null)
+    {
+        $this->baseURL = $baseURL;
+        $this->codec = $codec;
+        $this->headers = $headers;
+        $this->transport = $transport ?? \Closure::fromCallable([self::class, 'curl']);
+    }
+{{ range $method := $service.Methods }}
+{{- if $method.ServerStreaming }}
+    /**
+     * @return \Generator|{{ with $method.Response }}{{ . }}{{ else }}array{{ end }}[]
+     */
+    public function {{ $method.Name }}({{ with $method.Request }}{{ . }} {{ end }}$req): \Generator
+    {
+{{- template "objectRequest" $method }}
+        [$status, $body] = $this->post('{{ $method.Route }}', $req, 'application/x-ndjson');
+        foreach (explode("\n", $body) as $line) {
+            if (trim($line) !== '') {
+                {{ with $method.Response }}yield {{ . }}::fromArray($this->decode($status, $line, true));{{ else }}yield $this->decode($status, $line, true);{{ end }}
+            }
+        }
+    }
+{{- else }}
+    public function {{ $method.Name }}({{ with $method.Request }}{{ . }} {{ end }}$req){{ with $method.Response }}: {{ . }}{{ end }}
+    {
+{{- template "objectRequest" $method }}
+        [$status, $body] = $this->post('{{ $method.Route }}', $req, 'application/json');
+        {{ with $method.Response }}return {{ . }}::fromArray($this->decode($status, $body));{{ else }}return $this->decode($status, $body);{{ end }}
+    }
+{{- end }}
+{{ end }}
+    private function post(string $route, $req, string $accept): array
+    {
+        $url = $this->baseURL . $route;
+        $body = $req;
+        if ($this->codec === self::JSON_RPC) {
+            $url = $this->baseURL;
+            $body = ['jsonrpc' => '2.0', 'id' => ++self::$nextID, 'method' => $route, 'params' => $req];
+        }
+        $headers = array_merge($this->headers, ['Content-Type' => 'application/json', 'Accept' => $accept]);
+
+        return ($this->transport)($url, json_encode($body), $headers);
+    }
+
+    /**
+     * decode returns the response message written by the codec, or throws the error written instead.
+     * $streamed is set for the lines of a stream, which may be errors written once it has started.
+     */
+    private function decode(int $status, string $body, bool $streamed = false)
+    {
+        $data = json_decode($body, true);
+        if (json_last_error() !== JSON_ERROR_NONE) {
+            throw new {{ $service.Name }}Exception($body !== '' ? $body : "HTTP status $status", $status);
+        }
+
+        if ($this->codec === self::JSON_RPC) {
+            if (isset($data['error'])) {
+                throw new {{ $service.Name }}Exception($data['error']['message'] ?? '', $data['error']['code'] ?? 0, $data['error']['data'] ?? null);
+            }
+            return $data['result'] ?? [];
+        }
+        $failed = $status < 200 || $status >= 300;
+        // RESTCodec writes errors as {"error": "...", "code": ..., "message": "...", "details": [...]}
+        // with an error status, or as a line of a stream which has already started
+        if (($failed || $streamed) && is_array($data) && isset($data['error']) && is_string($data['error'])
+            && !array_diff(array_keys($data), ['error', 'code', 'message', 'details'])) {
+            throw new {{ $service.Name }}Exception($data['error'], $status, isset($data['code']) ? $data : null);
+        }
+        if ($failed) {
+            throw new {{ $service.Name }}Exception("HTTP status $status", $status);
+        }
+        return $data ?? [];
+    }
+
+    private static function curl(string $url, string $body, array $headers): array
+    {
+        $lines = [];
+        foreach ($headers as $name => $value) {
+            $lines[] = "$name: $value";
+        }
+
+        $ch = curl_init($url);
+        curl_setopt_array($ch, [
+            CURLOPT_POST => true,
+            CURLOPT_POSTFIELDS => $body,
+            CURLOPT_HTTPHEADER => $lines,
+            CURLOPT_RETURNTRANSFER => true,
+        ]);
+        $resp = curl_exec($ch);
+        if ($resp === false) {
+            $err = curl_error($ch);
+            curl_close($ch);
+            throw new {{ $service.Name }}Exception($err);
+        }
+        $status = curl_getinfo($ch, CURLINFO_RESPONSE_CODE);
+        curl_close($ch);
+
+        return [$status, $resp];
+    }
+}
+{{ end -}}
+`))
+)
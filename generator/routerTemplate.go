@@ -13,15 +13,36 @@ package {{ .Package }}
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/lazada/protoc-gen-go-http/codec"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	{{- if .WebSocket }}
+	"github.com/lazada/protoc-gen-go-http/runtime/websocket"
+	{{- end }}
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	{{- range .HandlerImports }}
+	{{ .String }}
+	{{- end }}
 )
 
+// Reference imports to suppress errors if they are not otherwise used.
+var _ grpc.ServerStream
+var _ = codes.OK
+var _ = status.Errorf
+
 type options struct {
 	routes		map[string]http.HandlerFunc
 	withSwagger bool
+	headerMatcher	runtime.HeaderMatcher
+	batchConcurrency	int
+	maxBatchLength	int
+	methodNaming	runtime.MethodNaming
+	{{- if .WebSocket }}
+	webSocket	[]websocket.Option
+	{{- end }}
 }
 
 func (o *options) validate() error {
@@ -34,7 +55,21 @@ func (o *options) validate() error {
 
 type option func(*options)
 
+// SwaggerPath is the path the Swagger document is served at with WithSwagger.
+const SwaggerPath = "/swagger.json"
+
+{{ if .Swagger -}}
+// swaggerJSON is the Swagger document of the services defined in the file.
+var swaggerJSON = []byte({{ printf "%q" .Swagger }})
+{{- else -}}
+// swaggerJSON is nil as the services could not be described by a Swagger document,
+// WithSwagger serves nothing.
+var swaggerJSON []byte
+{{- end }}
+
 // WithRoutes sets handlers to specific routes.
+// Routes of the "<HTTP method> <path template>" form, e.g. "GET /v1/{name=books/*}",
+// are matched against the request method and path.
 // Set the handler to nil to delete a route.
 func WithRoutes(routes map[string]http.HandlerFunc) option {
 	return func(opts *options) {
@@ -42,29 +77,78 @@ func WithRoutes(routes map[string]http.HandlerFunc) option {
 	}
 }
 
+// WithSwagger serves the Swagger document of the services at SwaggerPath.
 func WithSwagger() option {
 	return func(opts *options) {
 		opts.withSwagger = true
 	}
 }
 
+// WithHeaderMatcher sets which request headers are forwarded into the incoming gRPC metadata
+// of the methods, runtime.DefaultHeaderMatcher by default. A nil matcher forwards no headers.
+func WithHeaderMatcher(m runtime.HeaderMatcher) option {
+	return func(opts *options) {
+		opts.headerMatcher = m
+	}
+}
+
+// WithMethodNaming sets the naming scheme of the methods in JSON-RPC requests, runtime.{{ .MethodNaming }} by default.
+// Methods can always be called by their routes, e.g. "/example/getperson", as well.
+func WithMethodNaming(naming runtime.MethodNaming) option {
+	return func(opts *options) {
+		opts.methodNaming = naming
+	}
+}
+
+// WithBatchConcurrency serves up to n calls of a batch request, e.g. a JSON-RPC batch,
+// concurrently. They are served one at a time by default.
+func WithBatchConcurrency(n int) option {
+	return func(opts *options) {
+		opts.batchConcurrency = n
+	}
+}
+
+// WithMaxBatchLength rejects batch requests of more than n calls, codec.DefaultMaxBatchLength by default.
+// Their length is not limited if n is less than 1.
+func WithMaxBatchLength(n int) option {
+	return func(opts *options) {
+		opts.maxBatchLength = n
+	}
+}
+{{ if .WebSocket }}
+// WithWebSocketOptions configures the WebSocket connections of bidirectional streaming methods.
+func WithWebSocketOptions(wsOpts ...websocket.Option) option {
+	return func(opts *options) {
+		opts.webSocket = append(opts.webSocket, wsOpts...)
+	}
+}
+{{ end }}
 {{ range $sIdx, $service := .Services }}
 
 type {{ $service.Name }}Router struct {
 	srv				*http{{ $service.Name }}Server
 	codecBuilder	codec.CodecBuilder
 	routes			map[string]http.HandlerFunc
+	streams			map[string]bool
+	matcher			*runtime.Matcher
+	headerMatcher	runtime.HeaderMatcher
+	batchConcurrency	int
+	maxBatchLength	int
+	withSwagger		bool
 }
 
 func New{{ $service.Name }}Router(srv {{ $service.Name }}Server, codecBuilder codec.CodecBuilder, opts ...option) (*{{ $service.Name }}Router, error) {
 	out := &{{ $service.Name }}Router{
-		srv:			newHTTP{{ $service.Name }}Server(srv, codecBuilder()),
+		srv:			newHTTP{{ $service.Name }}Server(srv, codecBuilder),
 		codecBuilder:	codecBuilder,
 		routes:			make(map[string]http.HandlerFunc),
 	}
 
 	defaultOptions := &options{
-		routes:	make(map[string]http.HandlerFunc),
+		routes:			make(map[string]http.HandlerFunc),
+		headerMatcher:	runtime.DefaultHeaderMatcher,
+		maxBatchLength:	codec.DefaultMaxBatchLength,
+		methodNaming:	runtime.{{ $.MethodNaming }},
 	}
 
 	for _, opt := range opts {
@@ -74,10 +158,34 @@ func New{{ $service.Name }}Router(srv {{ $service.Name }}Server, codecBuilder co
 	if err := defaultOptions.validate(); err != nil {
 		return nil, err
 	}
+	out.withSwagger = defaultOptions.withSwagger
+	out.headerMatcher = defaultOptions.headerMatcher
+	out.batchConcurrency = defaultOptions.batchConcurrency
+	out.maxBatchLength = defaultOptions.maxBatchLength
+	{{- if $.WebSocket }}
+	out.srv.webSocket = defaultOptions.webSocket
+	{{- end }}
 
 	out.routes = map[string]http.HandlerFunc{
 		{{ range $hIdx, $handler := $service.Handlers }}"/{{ lower $service.Name }}/{{ lower $handler.Name }}": out.srv.{{ $handler.Name }},
-		{{ end }}
+		{{ range $binding := $handler.Bindings }}{{ printf "%q" $binding.Route }}: out.srv.{{ $handler.Name }},
+		{{ end }}{{ end }}
+	}
+
+	// the routes of streaming methods, which cannot be called in batches
+	out.streams = map[string]bool{
+		{{ range $hIdx, $handler := $service.Handlers }}{{ if not $handler.Unary }}"/{{ lower $service.Name }}/{{ lower $handler.Name }}": true,
+		{{ range $binding := $handler.Bindings }}{{ printf "%q" $binding.Route }}: true,
+		{{ end }}{{ end }}{{ end }}
+	}
+
+	if naming := defaultOptions.methodNaming; naming != nil {
+		{{- range $hIdx, $handler := $service.Handlers }}
+		out.routes[naming({{ printf "%q" $handler.FullMethod }})] = out.srv.{{ $handler.Name }}
+		{{- if not $handler.Unary }}
+		out.streams[naming({{ printf "%q" $handler.FullMethod }})] = true
+		{{- end }}
+		{{- end }}
 	}
 
 	for route, handler := range defaultOptions.routes {
@@ -85,12 +193,39 @@ func New{{ $service.Name }}Router(srv {{ $service.Name }}Server, codecBuilder co
 			continue
 		}
 		out.routes[route] = handler
+		delete(out.streams, route)
+	}
+
+	routes := make([]string, 0, len(out.routes))
+	for route := range out.routes {
+		routes = append(routes, route)
+	}
+
+	matcher, err := runtime.NewMatcher(routes)
+	if err != nil {
+		return nil, err
 	}
+	out.matcher = matcher
 
 	return out, nil
 }
 
 func (s *{{ $service.Name }}Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.withSwagger && swaggerJSON != nil && r.Method == http.MethodGet && r.URL.Path == SwaggerPath {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(swaggerJSON)
+		return
+	}
+
+	if codec.ServeBatch(w, r, s.codecBuilder, http.HandlerFunc(s.serve), s.batchConcurrency, s.maxBatchLength) {
+		return
+	}
+
+	s.serve(w, r)
+}
+
+// serve routes a single call to its handler.
+func (s *{{ $service.Name }}Router) serve(w http.ResponseWriter, r *http.Request) {
 	c := s.codecBuilder()
 
 	route, err := c.Route(r)
@@ -100,44 +235,207 @@ func (s *{{ $service.Name }}Router) ServeHTTP(w http.ResponseWriter, r *http.Req
 	}
 
 	handler, ok := s.routes[route]
+	// only routes which are the URL path are matched against path templates, JSON-RPC methods are not
+	if !ok && route == r.URL.Path {
+		pattern, params, matched := s.matcher.Match(r.Method, route)
+		if matched {
+			route = pattern
+			handler, ok = s.routes[route]
+			r = r.WithContext(runtime.WithRoute(r.Context(), pattern, params))
+		}
+	}
 	if !ok {
-		c.WriteError(w, fmt.Errorf("no handler for route %s", route))
+		c.WriteError(w, &codec.RouteNotFoundError{Route: route})
+		return
+	}
+	// the responses of streams cannot be written into the response of a batch
+	if s.streams[route] && codec.InBatch(r.Context()) {
+		c.WriteError(w, &codec.StreamInBatchError{Route: route})
 		return
 	}
+	if s.headerMatcher != nil {
+		r = r.WithContext(runtime.IncomingContext(r.Context(), r.Header, s.headerMatcher))
+	}
+	// the handler goes on with the codec which read the route, as it holds the state of the request
+	r = r.WithContext(codec.NewContext(r.Context(), c))
 
 	handler(w, r)
 }
 
 type http{{ $service.Name }}Server struct {
 	srv		{{ $service.Name }}Server
-	cdc	codec.Codec
+	codecBuilder	codec.CodecBuilder
+	{{- if $.WebSocket }}
+	webSocket	[]websocket.Option
+	{{- end }}
 }
 
-func newHTTP{{ $service.Name }}Server(srv {{ $service.Name }}Server, cdc codec.Codec) *http{{ $service.Name }}Server {
+func newHTTP{{ $service.Name }}Server(srv {{ $service.Name }}Server, codecBuilder codec.CodecBuilder) *http{{ $service.Name }}Server {
 	return &http{{ $service.Name }}Server{
-		srv: srv,
-		cdc: cdc,
+		srv:			srv,
+		codecBuilder:	codecBuilder,
+	}
+}
+
+// codecFor returns the codec the router read the route of the request with, a new one
+// if the handler is called otherwise. Codecs are never shared between requests.
+func (s *http{{ $service.Name }}Server) codecFor(r *http.Request) codec.Codec {
+	if cdc, ok := codec.FromContext(r.Context()); ok {
+		return cdc
 	}
+	return s.codecBuilder()
 }
 
 {{ range $hIdx, $handler := $service.Handlers }}
+{{ if $handler.Bidi }}
+func (s *http{{ $service.Name }}Server) {{ $handler.Name }}(w http.ResponseWriter, r *http.Request) {
+	cdc := s.codecFor(r)
+	stream, err := websocket.NewServerStream(w, r, cdc, {{ printf "%q" $handler.FullMethod }}, s.webSocket...)
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+	stream.Finish(s.srv.{{ $handler.Name }}(&http{{ $service.Name }}{{ $handler.Name }}Server{stream}))
+}
+
+type http{{ $service.Name }}{{ $handler.Name }}Server struct {
+	grpc.ServerStream
+}
+
+func (x *http{{ $service.Name }}{{ $handler.Name }}Server) Send(m *{{ $handler.Response }}) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *http{{ $service.Name }}{{ $handler.Name }}Server) Recv() (*{{ $handler.Arg }}, error) {
+	m := new({{ $handler.Arg }})
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{ else if $handler.ClientStreaming }}
+func (s *http{{ $service.Name }}Server) {{ $handler.Name }}(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	cdc := s.codecFor(r)
+	stream, err := runtime.NewClientStream(w, r, cdc, {{ printf "%q" $handler.FullMethod }})
+	if err != nil {
+		cdc.WriteError(w, err)
+		return
+	}
+
+	stream.Finish(s.srv.{{ $handler.Name }}(&http{{ $service.Name }}{{ $handler.Name }}Server{stream}))
+}
+
+type http{{ $service.Name }}{{ $handler.Name }}Server struct {
+	grpc.ServerStream
+}
+
+func (x *http{{ $service.Name }}{{ $handler.Name }}Server) SendAndClose(m *{{ $handler.Response }}) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *http{{ $service.Name }}{{ $handler.Name }}Server) Recv() (*{{ $handler.Arg }}, error) {
+	m := new({{ $handler.Arg }})
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{ else }}
 func (s *http{{ $service.Name }}Server) {{ $handler.Name }}(w http.ResponseWriter, r *http.Request) {
     defer r.Body.Close()
+	cdc := s.codecFor(r)
 	arg := {{ $handler.Arg }}{}
-	err := s.cdc.ReadRequest(r, &arg)
+	{{ if $handler.Bindings }}err := s.read{{ $handler.Name }}(r, cdc, &arg){{ else }}err := cdc.ReadRequest(r, &arg){{ end }}
     if err != nil {
-        s.cdc.WriteError(w, err)
+        cdc.WriteError(w, err)
 		return
     }
 
-	grpcResp, err := s.srv.{{ $handler.Name }}(r.Context(), &arg)
+	{{ if $handler.ServerStreaming }}
+	stream, err := runtime.NewServerStream(w, r, cdc, {{ printf "%q" $handler.FullMethod }})
 	if err != nil {
-        s.cdc.WriteError(w, err)
+		cdc.WriteError(w, err)
 		return
 	}
 
-	s.cdc.WriteResponse(w, grpcResp)
+	stream.Finish(s.srv.{{ $handler.Name }}(&arg, &http{{ $service.Name }}{{ $handler.Name }}Server{stream}))
+}
+
+type http{{ $service.Name }}{{ $handler.Name }}Server struct {
+	grpc.ServerStream
 }
+
+func (x *http{{ $service.Name }}{{ $handler.Name }}Server) Send(m *{{ $handler.Response }}) error {
+	return x.ServerStream.SendMsg(m)
+}
+	{{ else }}
+	ctx, transport := runtime.NewServerTransportStream(r.Context(), {{ printf "%q" $handler.FullMethod }})
+	grpcResp, err := s.srv.{{ $handler.Name }}(ctx, &arg)
+	transport.WriteHeader(w)
+	transport.WriteTrailer(w)
+	if err != nil {
+        cdc.WriteError(w, err)
+		return
+	}
+	{{ if $handler.ResponseBodies }}
+	route, _ := runtime.RouteFromContext(r.Context())
+	switch route {
+	{{- range $binding := $handler.Bindings }}{{ if $binding.ResponseBody }}
+	case {{ printf "%q" $binding.Route }}:
+		cdc.WriteResponse(w, {{ $binding.ResponseBody }})
+		return
+	{{- end }}{{ end }}
+	}
+	{{ end }}
+	cdc.WriteResponse(w, grpcResp)
+}
+	{{ end }}
+
+{{ if $handler.Bindings }}
+var (
+	{{- range $binding := $handler.Bindings }}
+	filter_{{ $service.Name }}_{{ $handler.Name }}_{{ $binding.Index }} = runtime.NewFilter({{ range $i, $f := $binding.Filter }}{{ if $i }}, {{ end }}{{ printf "%q" $f }}{{ end }})
+	{{- end }}
+)
+
+// read{{ $handler.Name }} reads the request body, the path and the query parameters as the matched route defines.
+func (s *http{{ $service.Name }}Server) read{{ $handler.Name }}(r *http.Request, cdc codec.Codec, arg *{{ $handler.Arg }}) error {
+	var err error
+
+	route, _ := runtime.RouteFromContext(r.Context())
+	switch route {
+	{{- range $binding := $handler.Bindings }}
+	case {{ printf "%q" $binding.Route }}:
+		{{- if $binding.Body }}
+		if err = cdc.ReadRequest(r, {{ $binding.Body }}); err != nil {
+			return err
+		}
+		{{- end }}
+		{{- if $binding.PathParams }}
+		params := runtime.PathParams(r.Context())
+		{{- end }}
+		{{- range $param := $binding.PathParams }}
+		if val, ok := params[{{ printf "%q" $param.Name }}]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing parameter %s", {{ printf "%q" $param.Name }})
+		} else if {{ if $param.Convert }}{{ $param.Assign }}, err = {{ $param.Convert }}(val){{ else }}err = runtime.PopulateFieldFromPath(arg, {{ printf "%q" $param.Name }}, val){{ end }}; err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", {{ printf "%q" $param.Name }}, err)
+		}
+		{{- end }}
+		{{- if $binding.Query }}
+		if err = runtime.PopulateQueryParameters(arg, r.URL.Query(), filter_{{ $service.Name }}_{{ $handler.Name }}_{{ $binding.Index }}); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		{{- end }}
+	{{- end }}
+	default:
+		return cdc.ReadRequest(r, arg)
+	}
+
+	return nil
+}
+{{ end }}
+{{ end }}
 {{ end }}
 
 {{ end }}
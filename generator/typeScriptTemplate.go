@@ -0,0 +1,171 @@
+package generator
+
+import (
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	TypeScriptTemplate = template.Must(template.New(`file`).Funcs(template.FuncMap{
+		`lowerFirst`: lowerFirst,
+	}).Parse(`// Code generated by protoc-gen-go-http. DO NOT EDIT.
+// source: {{ .Source }}
+{{ define "comment" }}{{ if . }}/**
+{{ range . }} * {{ . }}
+{{ end }} */
+{{ end }}{{ end }}
+{{- define "fieldComment" }}/**
+{{ range . }}   * {{ . }}
+{{ end }}   */
+  {{ end }}
+{{- range .Types }}
+{{ template "comment" .Comment }}
+{{- if .Alias }}export type {{ .Name }} = {{ .Alias }};
+{{ else }}export interface {{ .Name }} {
+{{- range .Fields }}
+  {{ with .Comment }}{{ template "fieldComment" . }}{{ end }}{{ .Name }}?: {{ .Type }};
+{{- end }}
+}
+{{ end }}
+{{- end }}
+/** Codec is the codec of the router the client calls. */
+export type Codec = "rest" | "jsonrpc";
+
+export interface ClientOptions {
+  /** codec defaults to "rest", for routers serving codec.NewRESTCCodec. */
+  codec?: Codec;
+  /** fetch defaults to the global fetch. */
+  fetch?: typeof fetch;
+  /** headers are sent with every request. */
+  headers?: Record<string, string>;
+}
+
+/** Status is the google.rpc.Status of an error: its gRPC code, message and details as typed JSON Any objects. */
+export interface Status {
+  code: number;
+  message: string;
+  details: Array<{ "@type": string; [field: string]: unknown }>;
+}
+
+/**
+ * HTTPError is an error returned by the router, or a response which cannot be decoded.
+ * code is the JSON-RPC error code, grpcStatus the gRPC status of the error if the router sends it.
+ */
+export class HTTPError extends Error {
+  constructor(message: string, readonly status: number, readonly code?: number, readonly grpcStatus?: Status) {
+    super(message);
+    this.name = "HTTPError";
+  }
+}
+
+const errorMembers = ["error", "code", "message", "details"];
+
+let nextID = 0;
+
+// invoke posts the request message to the route, in a JSON-RPC request if the codec is "jsonrpc".
+async function invoke(baseURL: string, options: ClientOptions, route: string, req: unknown, init?: RequestInit, accept?: string): Promise<Response> {
+  const jsonRPC = options.codec === "jsonrpc";
+  const headers: Record<string, string> = { "Content-Type": "application/json", ...options.headers };
+  if (accept) {
+    headers["Accept"] = accept;
+  }
+  const body = jsonRPC ? { jsonrpc: "2.0", id: ++nextID, method: route, params: req } : req;
+
+  return (options.fetch ?? fetch)(jsonRPC ? baseURL : baseURL + route, {
+    ...init,
+    method: "POST",
+    headers: { ...headers, ...(init?.headers as Record<string, string> | undefined) },
+    body: JSON.stringify(body),
+  });
+}
+
+// decode returns the response message written by the codec, or throws the error written instead.
+// streamed is set for the lines of a stream, which may be errors written once it has started.
+function decode<T>(options: ClientOptions, resp: Response, text: string, streamed = false): T {
+  let data: any;
+  try {
+    data = JSON.parse(text);
+  } catch (e) {
+    throw new HTTPError(text || resp.statusText, resp.status);
+  }
+
+  if (options.codec === "jsonrpc") {
+    if (data?.error) {
+      throw new HTTPError(data.error.message, resp.status, data.error.code, data.error.data);
+    }
+    return data?.result as T;
+  }
+  // RESTCodec writes errors as {"error": "...", "code": ..., "message": "...", "details": [...]}
+  // with an error status, or as a line of a stream which has already started
+  const isError = (!resp.ok || streamed) && data !== null && typeof data === "object" &&
+    typeof data.error === "string" && Object.keys(data).every((k) => errorMembers.includes(k));
+  if (isError || !resp.ok) {
+    const grpcStatus = isError && "code" in data ? { code: data.code, message: data.message, details: data.details ?? [] } : undefined;
+    throw new HTTPError(isError ? data.error : resp.statusText, resp.status, undefined, grpcStatus);
+  }
+  return data as T;
+}
+
+// lines yields the lines of a newline-delimited JSON response.
+async function* lines(resp: Response): AsyncGenerator<string> {
+  if (!resp.body) {
+    yield* (await resp.text()).split("\n").filter((line) => line.trim() !== "");
+    return;
+  }
+
+  const reader = resp.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = "";
+  for (;;) {
+    const { done, value } = await reader.read();
+    buf += decoder.decode(value, { stream: !done });
+    let i: number;
+    while ((i = buf.indexOf("\n")) >= 0) {
+      const line = buf.slice(0, i);
+      buf = buf.slice(i + 1);
+      if (line.trim() !== "") {
+        yield line;
+      }
+    }
+    if (done) {
+      break;
+    }
+  }
+  if (buf.trim() !== "") {
+    yield buf;
+  }
+}
+{{ range $service := .Services }}
+/**
+ * {{ $service.Name }}Client calls the {{ $service.Name }}Router served at baseURL.
+ * Server-streaming methods ask for newline-delimited JSON, client-streaming and
+ * bidirectional streaming ones are not supported.
+ */
+export class {{ $service.Name }}Client {
+  constructor(private readonly baseURL: string, private readonly options: ClientOptions = {}) {}
+{{ range $method := $service.Methods }}
+{{- if $method.ServerStreaming }}
+  async *{{ lowerFirst $method.Name }}(req: {{ $method.Request }}, init?: RequestInit): AsyncGenerator<{{ $method.Response }}> {
+    const resp = await invoke(this.baseURL, this.options, "{{ $method.Route }}", req, init, "application/x-ndjson");
+    for await (const line of lines(resp)) {
+      yield decode<{{ $method.Response }}>(this.options, resp, line, true);
+    }
+  }
+{{- else }}
+  async {{ lowerFirst $method.Name }}(req: {{ $method.Request }}, init?: RequestInit): Promise<{{ $method.Response }}> {
+    const resp = await invoke(this.baseURL, this.options, "{{ $method.Route }}", req, init);
+    return decode<{{ $method.Response }}>(this.options, resp, await resp.text());
+  }
+{{- end }}
+{{ end -}}
+}
+{{ end -}}
+`))
+)
+
+// lowerFirst returns s with its first letter lowered, e.g. getBook for GetBook.
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
@@ -1,17 +1,174 @@
 package generator
 
+import (
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+)
+
 type templateFileInfo struct {
 	Package  string
 	Services []*templateService
+	// Imports are the packages of the message types defined in other files.
+	Imports []descriptor.GoPackage
+	// AllImports are the packages of the message types of all the methods defined in other files.
+	AllImports []descriptor.GoPackage
+	// HandlerImports are the packages of the message types the handlers of the router refer to.
+	HandlerImports []descriptor.GoPackage
+	// UnaryImports are the packages of the request types of the unary methods.
+	UnaryImports []descriptor.GoPackage
+	// WebSocket is true if bidirectional streaming methods are served over WebSocket.
+	WebSocket bool
+	// Swagger is the compact Swagger document of the services, set for the router only.
+	// It is empty if the services cannot be described by one.
+	Swagger string
+	// MethodNaming is the runtime variable of the default naming scheme of JSON-RPC methods.
+	MethodNaming string
 }
 
 type templateService struct {
 	Name     string
 	Handlers []*templateHandler
+	// Methods are all the methods of the service, including ones without handlers.
+	Methods []*templateHandler
 }
 
 type templateHandler struct {
 	Name    string
 	Service string
-	Arg     string
+	// FullMethod is the gRPC name of the method, e.g. "/example.Example/GetPerson".
+	FullMethod string
+	Arg        string
+	// Response is the Go type of the response message.
+	Response        string
+	ServerStreaming bool
+	ClientStreaming bool
+	// Bindings are the method's google.api.http bindings.
+	Bindings []*templateBinding
+	// ResponseBodies is true if any binding writes a field of the response.
+	ResponseBodies bool
+}
+
+type templateBinding struct {
+	// Route is the pattern route the binding is mounted at.
+	Route      string
+	Index      int
+	PathParams []*templateParam
+	// Body is the expression the request body is decoded into, empty if the body is ignored.
+	Body string
+	// Query is false if all the fields are bound by the path or the body.
+	Query bool
+	// Filter lists the field paths which are not populated from query parameters.
+	Filter []string
+	// ResponseBody is the expression written as the response, empty for the whole response.
+	ResponseBody string
+}
+
+type templateParam struct {
+	// Name is the field path of the parameter, e.g. "parent.id".
+	Name string
+	// Assign and Convert are set when the parameter can be assigned directly,
+	// e.g. arg.Name, err = runtime.String(val).
+	Assign  string
+	Convert string
+}
+
+// Bidi reports whether the handler is a bidirectional streaming one.
+func (h *templateHandler) Bidi() bool {
+	return h.ServerStreaming && h.ClientStreaming
+}
+
+// Unary reports whether the handler is a simple request/response one.
+func (h *templateHandler) Unary() bool {
+	return !h.ServerStreaming && !h.ClientStreaming
+}
+
+type templateTSFile struct {
+	// Source is the name of the .proto file.
+	Source   string
+	Types    []*templateTSType
+	Services []*templateTSService
+}
+
+// templateTSType is an interface of a message, or an alias of an enum or a well-known type.
+type templateTSType struct {
+	Name    string
+	Comment []string
+	// Alias is the aliased type, empty for interfaces.
+	Alias  string
+	Fields []*templateTSField
+}
+
+type templateTSField struct {
+	Name    string
+	Type    string
+	Comment []string
+}
+
+type templateTSService struct {
+	Name    string
+	Methods []*templateTSMethod
+}
+
+type templateTSMethod struct {
+	Name string
+	// Route is the /<service>/<method> route the method is mounted at.
+	Route           string
+	Request         string
+	Response        string
+	ServerStreaming bool
+	// ObjectRequest is set for well-known requests encoded as JSON objects, e.g. google.protobuf.Empty,
+	// which callers may pass as empty arrays.
+	ObjectRequest bool
+}
+
+type templatePHPFile struct {
+	// Source is the name of the .proto file.
+	Source    string
+	Namespace string
+	Enums     []*templatePHPEnum
+	Messages  []*templatePHPMessage
+	Services  []*templatePHPService
+}
+
+type templatePHPEnum struct {
+	Name    string
+	Comment []string
+	Values  []string
+}
+
+type templatePHPMessage struct {
+	Name    string
+	Comment []string
+	Fields  []*templatePHPField
+}
+
+type templatePHPField struct {
+	Name string
+	// Type is the declared type of the property, empty for any JSON value.
+	Type    string
+	DocType string
+	Comment string
+	// Decode converts $value, the decoded JSON of the field, into the property value.
+	Decode string
+	// Encode is the JSON value of the property.
+	Encode string
+}
+
+type templatePHPService struct {
+	Name    string
+	Methods []*templatePHPMethod
+}
+
+type templatePHPMethod struct {
+	Name string
+	// Route is the /<service>/<method> route the method is mounted at.
+	Route string
+	// Request is the type of the request parameter, empty for well-known types.
+	Request string
+	// Response is the class of the response message, empty for well-known types,
+	// which are returned as decoded JSON.
+	Response        string
+	ServerStreaming bool
+	// ObjectRequest is set for well-known requests encoded as JSON objects, e.g. google.protobuf.Empty,
+	// which callers may pass as empty arrays.
+	ObjectRequest bool
 }
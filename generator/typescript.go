@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+	"github.com/lazada/protoc-gen-go-http/openapi"
+)
+
+func (g *generator) buildTypeScriptFiles(targets []*descriptor.File) (files []*plugin_go.CodeGeneratorResponse_File, err error) {
+	for _, file := range targets {
+		if len(file.Services) == 0 {
+			continue
+		}
+
+		tFile, err := g.typeScriptFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file.GetName(), err)
+		}
+		buf := bytes.NewBuffer(nil)
+		if err := TypeScriptTemplate.Execute(buf, tFile); err != nil {
+			return nil, err
+		}
+
+		var (
+			name = file.GetName()
+			base = strings.TrimSuffix(name, filepath.Ext(name))
+		)
+		files = append(files, &plugin_go.CodeGeneratorResponse_File{
+			Name:    proto.String(base + ".pb.http.ts"),
+			Content: proto.String(buf.String()),
+		})
+	}
+
+	return files, nil
+}
+
+// typeScriptFile returns the client of the unary and server-streaming methods of the file
+// services, the ones the Go client supports, with the types of their messages.
+func (g *generator) typeScriptFile(file *descriptor.File) (*templateTSFile, error) {
+	var (
+		pkg   = file.GetPackage()
+		tFile = &templateTSFile{Source: file.GetName()}
+		msgs  []*descriptor.Message
+	)
+	for _, svc := range file.Services {
+		tService := &templateTSService{Name: svc.GetName()}
+		for _, m := range svc.Methods {
+			if m.GetClientStreaming() {
+				continue
+			}
+			tService.Methods = append(tService.Methods, &templateTSMethod{
+				Name:            m.GetName(),
+				Route:           fmt.Sprintf("/%s/%s", strings.ToLower(svc.GetName()), strings.ToLower(m.GetName())),
+				Request:         tsName(m.RequestType.FQMN(), pkg),
+				Response:        tsName(m.ResponseType.FQMN(), pkg),
+				ServerStreaming: m.GetServerStreaming(),
+			})
+			msgs = append(msgs, m.RequestType, m.ResponseType)
+		}
+		tFile.Services = append(tFile.Services, tService)
+	}
+
+	defs, err := openapi.Definitions(g.reg, msgs, nil, openapi.WithOrigName(g.origName))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := defs[name]
+		tType := &templateTSType{
+			Name:    tsName(name, pkg),
+			Comment: docComment(s.Description),
+		}
+		if s.Type != "object" || s.AdditionalProperties != nil {
+			tType.Alias = tsType(s, pkg)
+			tFile.Types = append(tFile.Types, tType)
+			continue
+		}
+
+		props := make([]string, 0, len(s.Properties))
+		for prop := range s.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+		for _, prop := range props {
+			tType.Fields = append(tType.Fields, &templateTSField{
+				Name:    prop,
+				Type:    tsType(s.Properties[prop], pkg),
+				Comment: docComment(s.Properties[prop].Description),
+			})
+		}
+		tFile.Types = append(tFile.Types, tType)
+	}
+
+	return tFile, nil
+}
+
+// tsName returns the TypeScript name of a message or an enum: its name with the outer
+// messages, e.g. Owner_Address, qualified by the proto package if it is another one.
+func tsName(fqn, pkg string) string {
+	name := strings.TrimPrefix(fqn, ".")
+	if pkg != "" {
+		name = strings.TrimPrefix(name, pkg+".")
+	}
+	return strings.Replace(name, ".", "_", -1)
+}
+
+// tsType returns the TypeScript type of the values matching the schema.
+func tsType(s *openapi.Schema, pkg string) string {
+	switch {
+	case s.Ref != "":
+		return tsName(s.Ref, pkg)
+	case len(s.Enum) > 0:
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = strconv.Quote(v)
+		}
+		return strings.Join(values, " | ")
+	}
+
+	switch s.Type {
+	case "array":
+		elem := tsType(s.Items, pkg)
+		if strings.Contains(elem, " ") {
+			return "Array<" + elem + ">"
+		}
+		return elem + "[]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "{ [key: string]: " + tsType(s.AdditionalProperties, pkg) + " }"
+		}
+		return "{}"
+	case "string", "number", "boolean":
+		return s.Type
+	case "integer":
+		return "number"
+	}
+	return "unknown"
+}
+
+// docComment returns the lines of a /** */ doc comment, which cannot end the comment early.
+func docComment(description string) []string {
+	if description == "" {
+		return nil
+	}
+	return strings.Split(strings.Replace(description, "*/", "* /", -1), "\n")
+}
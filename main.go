@@ -18,6 +18,13 @@ var (
 	importPrefix      = flag.String("import_prefix", "", "prefix to be added to go package paths for imported proto files")
 	useRequestContext = flag.Bool("request_context", false, "determine whether to use http.Request's context or not")
 	allowDeleteBody   = flag.Bool("allow_delete_body", false, "unless set, HTTP DELETE methods may not have a body")
+	webSocket         = flag.Bool("websocket", false, "serve bidirectional streaming methods over WebSocket")
+	openAPI           = flag.String("openapi", "", "emit OpenAPI 3.1 documents per proto file or package: file, package")
+	jsonSchema        = flag.Bool("json_schema", false, "emit a JSON Schema document per message")
+	typeScript        = flag.Bool("typescript", false, "generate a TypeScript client per file")
+	php               = flag.Bool("php", false, "generate a PHP client per file")
+	origName          = flag.Bool("orig_name", false, "name fields as in the .proto file in API descriptions and clients")
+	methodNaming      = flag.String("method_naming", generator.MethodNamingPath, "naming scheme of the methods in JSON-RPC requests: path, service, snake, package")
 )
 
 func parseReq(r io.Reader) (*plugin_go.CodeGeneratorRequest, error) {
@@ -46,10 +53,23 @@ func main() {
 		log.Panic(err)
 	}
 	processParameters(req, reg)
+	if err := generator.ValidateMethodNaming(*methodNaming); err != nil {
+		emitError(err)
+		return
+	}
 
-	g := generator.New(reg, *useRequestContext)
+	g := generator.New(reg, *useRequestContext,
+		generator.WithWebSocket(*webSocket),
+		generator.WithOpenAPI(*openAPI),
+		generator.WithJSONSchema(*jsonSchema),
+		generator.WithTypeScript(*typeScript),
+		generator.WithPHP(*php),
+		generator.WithOrigName(*origName),
+		generator.WithMethodNaming(*methodNaming),
+	)
 
 	reg.SetPrefix(*importPrefix)
+	reg.SetAllowDeleteBody(*allowDeleteBody)
 	if err := reg.Load(req); err != nil {
 		emitError(err)
 		return
@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+)
+
+// Field numbers of the descriptor.proto messages used in source code info paths.
+const (
+	fileMessagePath = 4
+	fileEnumPath    = 5
+	fileServicePath = 6
+
+	messageFieldPath  = 2
+	messageNestedPath = 3
+	messageEnumPath   = 4
+
+	serviceMethodPath = 2
+)
+
+// comment returns the leading comment of the element at path in the file, trimmed.
+func (b *schemaBuilder) comment(file *descriptor.File, path []int32) string {
+	comments, ok := b.comments[file]
+	if !ok {
+		comments = make(map[string]string)
+		for _, loc := range file.GetSourceCodeInfo().GetLocation() {
+			if c := strings.TrimSpace(loc.GetLeadingComments()); c != "" {
+				comments[pathKey(loc.GetPath())] = c
+			}
+		}
+		b.comments[file] = comments
+	}
+	return comments[pathKey(path)]
+}
+
+func pathKey(path []int32) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = strconv.Itoa(int(p))
+	}
+	return strings.Join(parts, ".")
+}
+
+// outerPath returns the source code info path of the message nested
+// in the outer messages, not including the index of the message itself.
+func outerPath(file *descriptor.File, outers []string) []int32 {
+	path := []int32{fileMessagePath}
+	msgs := file.GetMessageType()
+	for _, name := range outers {
+		for i, m := range msgs {
+			if m.GetName() == name {
+				path = append(path, int32(i), messageNestedPath)
+				msgs = m.GetNestedType()
+				break
+			}
+		}
+	}
+	return path
+}
+
+func messagePath(m *descriptor.Message) []int32 {
+	return append(outerPath(m.File, m.Outers), int32(m.Index))
+}
+
+func enumPath(e *descriptor.Enum) []int32 {
+	if len(e.Outers) == 0 {
+		return []int32{fileEnumPath, int32(e.Index)}
+	}
+	path := outerPath(e.File, e.Outers)
+	// the enum belongs to the last outer message rather than to its nested messages
+	path[len(path)-1] = messageEnumPath
+	return append(path, int32(e.Index))
+}
+
+func methodPath(m *descriptor.Method) []int32 {
+	for i, svc := range m.Service.File.Services {
+		if svc != m.Service {
+			continue
+		}
+		for j, meth := range svc.Methods {
+			if meth == m {
+				return []int32{fileServicePath, int32(i), serviceMethodPath, int32(j)}
+			}
+		}
+	}
+	return nil
+}
+
+func servicePath(svc *descriptor.Service) []int32 {
+	for i, s := range svc.File.Services {
+		if s == svc {
+			return []int32{fileServicePath, int32(i)}
+		}
+	}
+	return nil
+}
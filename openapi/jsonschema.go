@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"encoding/json"
+
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+)
+
+// JSONSchemaDialect is the JSON Schema version the message schemas are written in.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+type jsonSchemaDocument struct {
+	Schema string             `json:"$schema"`
+	ID     string             `json:"$id"`
+	Title  string             `json:"title"`
+	Ref    string             `json:"$ref"`
+	Defs   map[string]*Schema `json:"$defs"`
+}
+
+// JSONSchemaName returns the name of the file the JSON Schema of the message is written to.
+func JSONSchemaName(m *descriptor.Message) string {
+	return definitionName(m.FQMN()) + ".schema.json"
+}
+
+// JSONSchema returns the JSON Schema document of the message in the proto3 JSON mapping.
+// The document is self-contained: the message and the messages and enums it refers to
+// are defined in its $defs.
+func JSONSchema(reg *descriptor.Registry, m *descriptor.Message, opts ...option) ([]byte, error) {
+	var (
+		b    = newSchemaBuilder(reg, "#/$defs/", true, opts)
+		name = definitionName(m.FQMN())
+		root = b.messageRef(m)
+	)
+	defs, err := b.definitions()
+	if err != nil {
+		return nil, err
+	}
+	if root.Ref == "" {
+		// well-known types are described inline rather than referred to
+		defs[name] = root
+	}
+
+	doc := &jsonSchemaDocument{
+		Schema: JSONSchemaDialect,
+		ID:     JSONSchemaName(m),
+		Title:  name,
+		Ref:    b.refPrefix + name,
+		Defs:   defs,
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Definitions returns the schemas of the messages and enums and of the messages and enums
+// they refer to, keyed by their definition names, the fully qualified names without the leading dot.
+// References are the bare definition names and oneof fields are plain properties.
+// Well-known types are only defined if they are among msgs.
+func Definitions(reg *descriptor.Registry, msgs []*descriptor.Message, enums []*descriptor.Enum, opts ...option) (map[string]*Schema, error) {
+	b := newSchemaBuilder(reg, "", false, opts)
+	inline := make(map[string]*Schema)
+	for _, m := range msgs {
+		if s := b.messageRef(m); s.Ref == "" {
+			inline[definitionName(m.FQMN())] = s
+		}
+	}
+	for _, e := range enums {
+		b.enumRef(e)
+	}
+
+	defs, err := b.definitions()
+	if err != nil {
+		return nil, err
+	}
+	for name, s := range inline {
+		defs[name] = s
+	}
+	return defs, nil
+}
+
+// IsWellKnownType reports whether the message is a well-known type with a special JSON
+// representation, described inline rather than defined.
+func IsWellKnownType(fqmn string) bool {
+	_, ok := wellKnownSchemas[fqmn]
+	return ok
+}
@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+)
+
+type openAPIDocument struct {
+	OpenAPI    string                      `json:"openapi"`
+	Info       info                        `json:"info"`
+	Tags       []tag                       `json:"tags,omitempty"`
+	Paths      map[string]*openAPIPathItem `json:"paths"`
+	Components openAPIComponents           `json:"components"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+type openAPIPathItem struct {
+	Get     *openAPIOperation `json:"get,omitempty"`
+	Put     *openAPIOperation `json:"put,omitempty"`
+	Post    *openAPIOperation `json:"post,omitempty"`
+	Delete  *openAPIOperation `json:"delete,omitempty"`
+	Options *openAPIOperation `json:"options,omitempty"`
+	Head    *openAPIOperation `json:"head,omitempty"`
+	Patch   *openAPIOperation `json:"patch,omitempty"`
+	Trace   *openAPIOperation `json:"trace,omitempty"`
+}
+
+type openAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty"`
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Description string                       `json:"description,omitempty"`
+	Required    bool                         `json:"required"`
+	Content     map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// OpenAPI returns the OpenAPI 3.1 document of the services defined in the files,
+// titled with title. Operations are described as by Swagger, and proto oneofs
+// are expressed with oneOf.
+func OpenAPI(reg *descriptor.Registry, files []*descriptor.File, title string, opts ...option) ([]byte, error) {
+	b := newSchemaBuilder(reg, "#/components/schemas/", true, opts)
+	doc := &openAPIDocument{
+		OpenAPI: "3.1.0",
+		Info:    info{Title: title, Version: "version not set"},
+		Paths:   make(map[string]*openAPIPathItem),
+	}
+
+	for _, file := range files {
+		doc.Tags = append(doc.Tags, b.tags(file)...)
+
+		ops, err := b.operations(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file.GetName(), err)
+		}
+		for _, op := range ops {
+			if err := addOpenAPIOperation(doc, op, b.openAPIOperation(op)); err != nil {
+				return nil, fmt.Errorf("%s: %v", file.GetName(), err)
+			}
+		}
+	}
+
+	schemas, err := b.definitions()
+	if err != nil {
+		return nil, err
+	}
+	schemas[errorDefinition] = errorSchema()
+	doc.Components.Schemas = schemas
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func addOpenAPIOperation(doc *openAPIDocument, op *operation, openAPIOp *openAPIOperation) error {
+	item, ok := doc.Paths[op.path]
+	if !ok {
+		item = &openAPIPathItem{}
+	}
+
+	var slot **openAPIOperation
+	switch op.httpMethod {
+	case "GET":
+		slot = &item.Get
+	case "PUT":
+		slot = &item.Put
+	case "POST":
+		slot = &item.Post
+	case "DELETE":
+		slot = &item.Delete
+	case "OPTIONS":
+		slot = &item.Options
+	case "HEAD":
+		slot = &item.Head
+	case "PATCH":
+		slot = &item.Patch
+	case "TRACE":
+		slot = &item.Trace
+	default:
+		// OpenAPI cannot describe custom HTTP methods
+		return nil
+	}
+	if *slot != nil {
+		return fmt.Errorf("%s %s is already described by %s", op.httpMethod, op.path, (*slot).OperationID)
+	}
+	*slot = openAPIOp
+	doc.Paths[op.path] = item
+
+	return nil
+}
+
+func (b *schemaBuilder) openAPIOperation(op *operation) *openAPIOperation {
+	openAPIOp := &openAPIOperation{
+		Summary:     op.summary,
+		OperationID: op.id,
+		Tags:        []string{op.method.Service.GetName()},
+		Responses:   make(map[string]*openAPIResponse),
+	}
+
+	for _, p := range op.pathParams {
+		param := newOpenAPIParameter(p, "path")
+		param.Required = true
+		openAPIOp.Parameters = append(openAPIOp.Parameters, param)
+	}
+	for _, p := range op.queryParams {
+		openAPIOp.Parameters = append(openAPIOp.Parameters, newOpenAPIParameter(p, "query"))
+	}
+
+	if op.body != nil {
+		body := &openAPIRequestBody{
+			Required: true,
+			Content:  map[string]*openAPIMediaType{"application/json": {Schema: op.body}},
+		}
+		if op.method.GetClientStreaming() {
+			body.Description = requestStreamDescription
+			body.Content = map[string]*openAPIMediaType{runtime.ContentTypeNDJSON: {Schema: op.body}}
+		}
+		openAPIOp.RequestBody = body
+	}
+
+	resp := &openAPIResponse{
+		Description: "A successful response.",
+		Content:     map[string]*openAPIMediaType{"application/json": {Schema: op.response}},
+	}
+	if op.method.GetServerStreaming() {
+		resp.Description = responseStreamDescription
+		resp.Content = map[string]*openAPIMediaType{
+			runtime.ContentTypeSSE:    {Schema: op.response},
+			runtime.ContentTypeNDJSON: {Schema: op.response},
+		}
+	}
+	openAPIOp.Responses["200"] = resp
+	openAPIOp.Responses["default"] = &openAPIResponse{
+		Description: "An error.",
+		Content: map[string]*openAPIMediaType{
+			"application/json": {Schema: &Schema{Ref: b.refPrefix + errorDefinition}},
+		},
+	}
+
+	return openAPIOp
+}
+
+func newOpenAPIParameter(p *parameter, in string) *openAPIParameter {
+	schema := p.schema
+	if p.repeated {
+		// query arrays are exploded into repeated keys by default
+		schema = &Schema{Type: "array", Items: p.schema}
+	}
+	return &openAPIParameter{
+		Name:        p.name,
+		In:          in,
+		Description: p.description,
+		Schema:      schema,
+	}
+}
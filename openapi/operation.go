@@ -0,0 +1,214 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	gendesc "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+)
+
+// operation is a method served at a route, whatever document format describes it.
+type operation struct {
+	method *descriptor.Method
+	// httpMethod and path are where the method is served, path has the variables
+	// reduced to their field paths, e.g. "/v1/{name}".
+	httpMethod string
+	path       string
+	id         string
+	summary    string
+
+	pathParams  []*parameter
+	queryParams []*parameter
+	// body is the schema of the request body, nil if the body is ignored.
+	body *Schema
+	// response is the schema of the response body.
+	response *Schema
+}
+
+// parameter is a field set from a path variable or a query parameter.
+type parameter struct {
+	name        string
+	description string
+	// schema is the schema of a single value, it is inlined rather than referred to.
+	schema   *Schema
+	repeated bool
+}
+
+// operations returns the operations of the methods of the file services.
+// Methods annotated with google.api.http are served at their bindings, others
+// at the /<service>/<method> routes they are mounted at by the generated router.
+// Bidirectional streaming methods are skipped.
+func (b *schemaBuilder) operations(file *descriptor.File) ([]*operation, error) {
+	var ops []*operation
+	for _, svc := range file.Services {
+		for _, m := range svc.Methods {
+			if m.GetClientStreaming() && m.GetServerStreaming() {
+				continue
+			}
+
+			if len(m.Bindings) == 0 {
+				op, err := b.operation(m, nil)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %v", svc.GetName(), m.GetName(), err)
+				}
+				ops = append(ops, op)
+				continue
+			}
+			for _, binding := range m.Bindings {
+				op, err := b.operation(m, binding)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %v", svc.GetName(), m.GetName(), err)
+				}
+				ops = append(ops, op)
+			}
+		}
+	}
+	return ops, nil
+}
+
+// operation describes the method at the binding, at its /<service>/<method> route if binding is nil.
+func (b *schemaBuilder) operation(m *descriptor.Method, binding *descriptor.Binding) (*operation, error) {
+	op := &operation{
+		method:     m,
+		httpMethod: "POST",
+		path:       fmt.Sprintf("/%s/%s", strings.ToLower(m.Service.GetName()), strings.ToLower(m.GetName())),
+		id:         fmt.Sprintf("%s_%s", m.Service.GetName(), m.GetName()),
+		summary:    b.comment(m.Service.File, methodPath(m)),
+	}
+	body := &descriptor.Body{}
+	var responseBody *descriptor.Body
+	if binding != nil {
+		op.httpMethod, op.path = binding.HTTPMethod, templatePath(binding.PathTmpl)
+		if binding.Index > 0 {
+			op.id = fmt.Sprintf("%s_%d", op.id, binding.Index)
+		}
+		body, responseBody = binding.Body, binding.ResponseBody
+	}
+
+	bound := make(map[string]bool)
+	if binding != nil {
+		for _, p := range binding.PathParams {
+			s, err := b.parameterSchema(p.Target)
+			if err != nil {
+				return nil, err
+			}
+			op.pathParams = append(op.pathParams, &parameter{
+				name:        p.FieldPath.String(),
+				description: s.Description,
+				schema:      s,
+			})
+			bound[p.FieldPath.String()] = true
+		}
+	}
+
+	if body != nil {
+		op.body = b.messageRef(m.RequestType)
+		if len(body.FieldPath) > 0 {
+			field := body.FieldPath[0].Target
+			s, err := b.fieldSchema(field)
+			if err != nil {
+				return nil, err
+			}
+			op.body = s
+			bound[field.GetName()] = true
+		}
+	}
+
+	if body == nil || len(body.FieldPath) > 0 {
+		params, err := b.queryParameters(m.RequestType, "", bound, nil)
+		if err != nil {
+			return nil, err
+		}
+		op.queryParams = params
+	}
+
+	op.response = b.messageRef(m.ResponseType)
+	if responseBody != nil {
+		s, err := b.fieldSchema(responseBody.FieldPath[0].Target)
+		if err != nil {
+			return nil, err
+		}
+		op.response = s
+	}
+
+	return op, nil
+}
+
+// queryParameters returns the parameters populated from the query for the fields of the message
+// which are not bound, with nested fields named by their dotted paths.
+func (b *schemaBuilder) queryParameters(msg *descriptor.Message, prefix string, bound map[string]bool, outers []string) ([]*parameter, error) {
+	for _, outer := range outers {
+		if outer == msg.FQMN() {
+			// recursive messages cannot be set from the query completely
+			return nil, nil
+		}
+	}
+	outers = append(outers, msg.FQMN())
+
+	var params []*parameter
+	for i, f := range msg.Fields {
+		name := prefix + f.GetName()
+		if bound[name] {
+			continue
+		}
+
+		if f.GetType() == gendesc.FieldDescriptorProto_TYPE_MESSAGE && !descriptor.IsWellKnownType(f.GetTypeName()) {
+			m, err := b.reg.LookupMsg(msg.FQMN(), f.GetTypeName())
+			if err != nil {
+				return nil, err
+			}
+			if m.GetOptions().GetMapEntry() || f.GetLabel() == gendesc.FieldDescriptorProto_LABEL_REPEATED {
+				continue
+			}
+			nested, err := b.queryParameters(m, name+".", bound, outers)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, nested...)
+			continue
+		}
+
+		s, err := b.parameterSchema(f)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, &parameter{
+			name:        name,
+			description: b.comment(msg.File, append(messagePath(msg), messageFieldPath, int32(i))),
+			schema:      s,
+			repeated:    f.GetLabel() == gendesc.FieldDescriptorProto_LABEL_REPEATED,
+		})
+	}
+	return params, nil
+}
+
+// parameterSchema returns the schema of a single value of a field set from a string,
+// with enums inlined as parameters cannot refer to definitions.
+func (b *schemaBuilder) parameterSchema(f *descriptor.Field) (*Schema, error) {
+	switch f.GetType() {
+	case gendesc.FieldDescriptorProto_TYPE_ENUM:
+		e, err := b.reg.LookupEnum(f.Message.FQMN(), f.GetTypeName())
+		if err != nil {
+			return nil, err
+		}
+		return b.enumSchema(e), nil
+	case gendesc.FieldDescriptorProto_TYPE_MESSAGE:
+		if s, ok := wellKnownSchemas[f.GetTypeName()]; ok {
+			return s(), nil
+		}
+		return nil, fmt.Errorf("field %s cannot be set from a string", f.GetName())
+	}
+	return b.singularSchema(f)
+}
+
+var variablePattern = regexp.MustCompile(`{([^=}]+)(=[^}]*)?}`)
+
+// templatePath returns the path template of the pattern with variables reduced to
+// their field paths, e.g. "/v1/{name}" for "GET /v1/{name=books/*}".
+func templatePath(p runtime.Pattern) string {
+	tmpl := strings.TrimPrefix(p.String(), p.Method()+" ")
+	return variablePattern.ReplaceAllString(tmpl, "{$1}")
+}
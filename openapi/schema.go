@@ -0,0 +1,332 @@
+// Package openapi builds API descriptions of the services in a descriptor.Registry.
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gendesc "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+)
+
+// Schema is a JSON Schema of a message, a field or a parameter.
+// The composition keywords, which Swagger 2.0 lacks, are only used for oneofs.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Default              string             `json:"default,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Not                  *Schema            `json:"not,omitempty"`
+}
+
+// schemaBuilder builds schemas of messages, referring to other messages and enums
+// by their definition names prefixed with refPrefix. Referred types are collected
+// so that their definitions can be built afterwards.
+type schemaBuilder struct {
+	reg       *descriptor.Registry
+	refPrefix string
+	// oneofs is true if the fields of oneofs are described as oneOf alternatives
+	// rather than as plain properties.
+	oneofs bool
+	// origName is true if fields are named as in the .proto file rather than by their JSON names.
+	origName bool
+	comments map[*descriptor.File]map[string]string
+
+	messages map[string]*descriptor.Message
+	enums    map[string]*descriptor.Enum
+}
+
+type option func(*schemaBuilder)
+
+// WithOrigName describes fields by their names in the .proto file rather than by their JSON names,
+// as the codecs write them with codec.WithOrigName.
+func WithOrigName(enabled bool) option {
+	return func(b *schemaBuilder) {
+		b.origName = enabled
+	}
+}
+
+func newSchemaBuilder(reg *descriptor.Registry, refPrefix string, oneofs bool, opts []option) *schemaBuilder {
+	b := &schemaBuilder{
+		reg:       reg,
+		refPrefix: refPrefix,
+		oneofs:    oneofs,
+		comments:  make(map[*descriptor.File]map[string]string),
+		messages:  make(map[string]*descriptor.Message),
+		enums:     make(map[string]*descriptor.Enum),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// FieldName returns the name of the field in the proto3 JSON mapping: its JSON name,
+// e.g. pageSize for page_size, or its name in the .proto file if origName is true.
+func FieldName(f *descriptor.Field, origName bool) string {
+	if origName {
+		return f.GetName()
+	}
+	if name := f.GetJsonName(); name != "" {
+		return name
+	}
+
+	// protoc sets the JSON names, they are computed the same way otherwise
+	var (
+		name  []byte
+		upper bool
+	)
+	for i := 0; i < len(f.GetName()); i++ {
+		c := f.GetName()[i]
+		switch {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			name = append(name, c-'a'+'A')
+			upper = false
+		default:
+			name = append(name, c)
+			upper = false
+		}
+	}
+	return string(name)
+}
+
+// definitionName returns the name a message or an enum is defined with,
+// its fully qualified name without the leading dot.
+func definitionName(fqn string) string {
+	return strings.TrimPrefix(fqn, ".")
+}
+
+// messageRef returns the schema of a field of the message type:
+// an inline schema for well-known types, a reference otherwise.
+func (b *schemaBuilder) messageRef(m *descriptor.Message) *Schema {
+	if s, ok := wellKnownSchemas[m.FQMN()]; ok {
+		return s()
+	}
+	name := definitionName(m.FQMN())
+	b.messages[name] = m
+	return &Schema{Ref: b.refPrefix + name}
+}
+
+func (b *schemaBuilder) enumRef(e *descriptor.Enum) *Schema {
+	name := definitionName(e.FQEN())
+	b.enums[name] = e
+	return &Schema{Ref: b.refPrefix + name}
+}
+
+// definitions returns the schemas of all the referred messages and enums, keyed by their names.
+func (b *schemaBuilder) definitions() (map[string]*Schema, error) {
+	defs := make(map[string]*Schema)
+	for {
+		var pending []string
+		for name := range b.messages {
+			if _, ok := defs[name]; !ok {
+				pending = append(pending, name)
+			}
+		}
+		for name := range b.enums {
+			if _, ok := defs[name]; !ok {
+				pending = append(pending, name)
+			}
+		}
+		if len(pending) == 0 {
+			return defs, nil
+		}
+
+		sort.Strings(pending)
+		for _, name := range pending {
+			if e, ok := b.enums[name]; ok {
+				defs[name] = b.enumSchema(e)
+				continue
+			}
+			s, err := b.messageSchema(b.messages[name])
+			if err != nil {
+				return nil, err
+			}
+			defs[name] = s
+		}
+	}
+}
+
+// messageSchema returns the schema of the message itself, as opposed to a reference to it.
+func (b *schemaBuilder) messageSchema(m *descriptor.Message) (*Schema, error) {
+	s := &Schema{
+		Type:        "object",
+		Description: b.comment(m.File, messagePath(m)),
+	}
+	oneofs := make([]*Schema, len(m.GetOneofDecl()))
+	for i, f := range m.Fields {
+		fs, err := b.fieldSchema(f)
+		if err != nil {
+			return nil, err
+		}
+		if fs.Ref == "" {
+			fs.Description = b.comment(m.File, append(messagePath(m), messageFieldPath, int32(i)))
+		}
+
+		name := FieldName(f, b.origName)
+		if b.oneofs && f.OneofIndex != nil {
+			oneof := oneofs[f.GetOneofIndex()]
+			if oneof == nil {
+				oneof = &Schema{Not: &Schema{}}
+				oneofs[f.GetOneofIndex()] = oneof
+			}
+			oneof.OneOf = append(oneof.OneOf, &Schema{
+				Title:      name,
+				Properties: map[string]*Schema{name: fs},
+				Required:   []string{name},
+			})
+			oneof.Not.AnyOf = append(oneof.Not.AnyOf, &Schema{Required: []string{name}})
+			continue
+		}
+
+		if s.Properties == nil {
+			s.Properties = make(map[string]*Schema)
+		}
+		s.Properties[name] = fs
+	}
+
+	// exactly one of the fields or none of them is set
+	var constraints []*Schema
+	for _, oneof := range oneofs {
+		if oneof == nil {
+			continue
+		}
+		none := &Schema{Title: "none", Not: oneof.Not}
+		constraints = append(constraints, &Schema{OneOf: append(oneof.OneOf, none)})
+	}
+	switch len(constraints) {
+	case 0:
+	case 1:
+		s.OneOf = constraints[0].OneOf
+	default:
+		s.AllOf = constraints
+	}
+
+	return s, nil
+}
+
+func (b *schemaBuilder) enumSchema(e *descriptor.Enum) *Schema {
+	s := &Schema{
+		Type:        "string",
+		Description: b.comment(e.File, enumPath(e)),
+	}
+	for _, v := range e.GetValue() {
+		s.Enum = append(s.Enum, v.GetName())
+	}
+	if len(s.Enum) > 0 {
+		s.Default = s.Enum[0]
+	}
+	return s
+}
+
+// fieldSchema returns the schema of the field value, an array for repeated fields
+// and an object for maps.
+func (b *schemaBuilder) fieldSchema(f *descriptor.Field) (*Schema, error) {
+	if f.GetType() == gendesc.FieldDescriptorProto_TYPE_MESSAGE {
+		m, err := b.reg.LookupMsg(f.Message.FQMN(), f.GetTypeName())
+		if err != nil {
+			return nil, err
+		}
+		if m.GetOptions().GetMapEntry() {
+			return b.mapSchema(m)
+		}
+		return b.repeated(f, b.messageRef(m)), nil
+	}
+
+	s, err := b.singularSchema(f)
+	if err != nil {
+		return nil, err
+	}
+	return b.repeated(f, s), nil
+}
+
+func (b *schemaBuilder) mapSchema(entry *descriptor.Message) (*Schema, error) {
+	for _, f := range entry.Fields {
+		if f.GetName() != "value" {
+			continue
+		}
+		value, err := b.fieldSchema(f)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: value}, nil
+	}
+	return nil, fmt.Errorf("map entry %s has no value field", entry.FQMN())
+}
+
+func (b *schemaBuilder) repeated(f *descriptor.Field, s *Schema) *Schema {
+	if f.GetLabel() != gendesc.FieldDescriptorProto_LABEL_REPEATED {
+		return s
+	}
+	return &Schema{Type: "array", Items: s}
+}
+
+// singularSchema returns the schema of a single value of a scalar or enum field.
+func (b *schemaBuilder) singularSchema(f *descriptor.Field) (*Schema, error) {
+	if f.GetType() == gendesc.FieldDescriptorProto_TYPE_ENUM {
+		e, err := b.reg.LookupEnum(f.Message.FQMN(), f.GetTypeName())
+		if err != nil {
+			return nil, err
+		}
+		return b.enumRef(e), nil
+	}
+
+	t, ok := scalarSchemas[f.GetType()]
+	if !ok {
+		return nil, fmt.Errorf("field %s.%s has unsupported type %s", f.Message.FQMN(), f.GetName(), f.GetType())
+	}
+	return &Schema{Type: t[0], Format: t[1]}, nil
+}
+
+// scalarSchemas are the types and formats of scalar fields in the proto3 JSON mapping.
+var scalarSchemas = map[gendesc.FieldDescriptorProto_Type][2]string{
+	gendesc.FieldDescriptorProto_TYPE_DOUBLE:   {"number", "double"},
+	gendesc.FieldDescriptorProto_TYPE_FLOAT:    {"number", "float"},
+	gendesc.FieldDescriptorProto_TYPE_INT64:    {"string", "int64"},
+	gendesc.FieldDescriptorProto_TYPE_UINT64:   {"string", "uint64"},
+	gendesc.FieldDescriptorProto_TYPE_INT32:    {"integer", "int32"},
+	gendesc.FieldDescriptorProto_TYPE_FIXED64:  {"string", "uint64"},
+	gendesc.FieldDescriptorProto_TYPE_FIXED32:  {"integer", "int64"},
+	gendesc.FieldDescriptorProto_TYPE_BOOL:     {"boolean", ""},
+	gendesc.FieldDescriptorProto_TYPE_STRING:   {"string", ""},
+	gendesc.FieldDescriptorProto_TYPE_BYTES:    {"string", "byte"},
+	gendesc.FieldDescriptorProto_TYPE_UINT32:   {"integer", "int64"},
+	gendesc.FieldDescriptorProto_TYPE_SFIXED32: {"integer", "int32"},
+	gendesc.FieldDescriptorProto_TYPE_SFIXED64: {"string", "int64"},
+	gendesc.FieldDescriptorProto_TYPE_SINT32:   {"integer", "int32"},
+	gendesc.FieldDescriptorProto_TYPE_SINT64:   {"string", "int64"},
+}
+
+// wellKnownSchemas build the schemas of well-known types, which have special JSON representations.
+var wellKnownSchemas = map[string]func() *Schema{
+	".google.protobuf.Timestamp":   func() *Schema { return &Schema{Type: "string", Format: "date-time"} },
+	".google.protobuf.Duration":    func() *Schema { return &Schema{Type: "string"} },
+	".google.protobuf.FieldMask":   func() *Schema { return &Schema{Type: "string"} },
+	".google.protobuf.Empty":       func() *Schema { return &Schema{Type: "object"} },
+	".google.protobuf.Struct":      func() *Schema { return &Schema{Type: "object", AdditionalProperties: &Schema{}} },
+	".google.protobuf.Value":       func() *Schema { return &Schema{} },
+	".google.protobuf.ListValue":   func() *Schema { return &Schema{Type: "array", Items: &Schema{}} },
+	".google.protobuf.Any":         func() *Schema { return &Schema{Type: "object", AdditionalProperties: &Schema{}} },
+	".google.protobuf.DoubleValue": func() *Schema { return &Schema{Type: "number", Format: "double"} },
+	".google.protobuf.FloatValue":  func() *Schema { return &Schema{Type: "number", Format: "float"} },
+	".google.protobuf.Int64Value":  func() *Schema { return &Schema{Type: "string", Format: "int64"} },
+	".google.protobuf.UInt64Value": func() *Schema { return &Schema{Type: "string", Format: "uint64"} },
+	".google.protobuf.Int32Value":  func() *Schema { return &Schema{Type: "integer", Format: "int32"} },
+	".google.protobuf.UInt32Value": func() *Schema { return &Schema{Type: "integer", Format: "int64"} },
+	".google.protobuf.BoolValue":   func() *Schema { return &Schema{Type: "boolean"} },
+	".google.protobuf.StringValue": func() *Schema { return &Schema{Type: "string"} },
+	".google.protobuf.BytesValue":  func() *Schema { return &Schema{Type: "string", Format: "byte"} },
+}
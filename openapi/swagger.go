@@ -0,0 +1,244 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lazada/protoc-gen-go-http/descriptor"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+)
+
+type swaggerDocument struct {
+	Swagger     string                      `json:"swagger"`
+	Info        info                        `json:"info"`
+	Tags        []tag                       `json:"tags,omitempty"`
+	Consumes    []string                    `json:"consumes"`
+	Produces    []string                    `json:"produces"`
+	Paths       map[string]*swaggerPathItem `json:"paths"`
+	Definitions map[string]*Schema          `json:"definitions,omitempty"`
+}
+
+type info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type swaggerPathItem struct {
+	Get     *swaggerOperation `json:"get,omitempty"`
+	Put     *swaggerOperation `json:"put,omitempty"`
+	Post    *swaggerOperation `json:"post,omitempty"`
+	Delete  *swaggerOperation `json:"delete,omitempty"`
+	Options *swaggerOperation `json:"options,omitempty"`
+	Head    *swaggerOperation `json:"head,omitempty"`
+	Patch   *swaggerOperation `json:"patch,omitempty"`
+}
+
+type swaggerOperation struct {
+	Summary     string                      `json:"summary,omitempty"`
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Consumes    []string                    `json:"consumes,omitempty"`
+	Produces    []string                    `json:"produces,omitempty"`
+	Parameters  []*swaggerParameter         `json:"parameters,omitempty"`
+	Responses   map[string]*swaggerResponse `json:"responses"`
+}
+
+type swaggerParameter struct {
+	Name             string   `json:"name"`
+	In               string   `json:"in"`
+	Description      string   `json:"description,omitempty"`
+	Required         bool     `json:"required,omitempty"`
+	Type             string   `json:"type,omitempty"`
+	Format           string   `json:"format,omitempty"`
+	Items            *Schema  `json:"items,omitempty"`
+	CollectionFormat string   `json:"collectionFormat,omitempty"`
+	Enum             []string `json:"enum,omitempty"`
+	Schema           *Schema  `json:"schema,omitempty"`
+}
+
+type swaggerResponse struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// errorDefinition is the name of the definition of errors written by RESTCodec.
+const errorDefinition = "httpError"
+
+// Descriptions of the streams of messages.
+const (
+	requestStreamDescription  = "Messages sent as newline-delimited JSON."
+	responseStreamDescription = "A stream of messages sent as Server-Sent Events, or as newline-delimited JSON if accepted."
+)
+
+// Swagger returns the Swagger 2.0 document of the services defined in the file.
+// Methods annotated with google.api.http are described at their bindings, others
+// at the /<service>/<method> routes they are mounted at by the generated router.
+// Bidirectional streaming methods and custom HTTP methods are not described.
+func Swagger(reg *descriptor.Registry, file *descriptor.File, opts ...option) ([]byte, error) {
+	b := newSchemaBuilder(reg, "#/definitions/", false, opts)
+	doc := &swaggerDocument{
+		Swagger:  "2.0",
+		Info:     info{Title: file.GetName(), Version: "version not set"},
+		Tags:     b.tags(file),
+		Consumes: []string{"application/json"},
+		Produces: []string{"application/json"},
+		Paths:    make(map[string]*swaggerPathItem),
+	}
+
+	ops, err := b.operations(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if err := addSwaggerOperation(doc, op, b.swaggerOperation(op)); err != nil {
+			return nil, err
+		}
+	}
+
+	defs, err := b.definitions()
+	if err != nil {
+		return nil, err
+	}
+	defs[errorDefinition] = errorSchema()
+	doc.Definitions = defs
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (b *schemaBuilder) tags(file *descriptor.File) []tag {
+	var tags []tag
+	for _, svc := range file.Services {
+		tags = append(tags, tag{
+			Name:        svc.GetName(),
+			Description: b.comment(file, servicePath(svc)),
+		})
+	}
+	return tags
+}
+
+func addSwaggerOperation(doc *swaggerDocument, op *operation, swaggerOp *swaggerOperation) error {
+	item, ok := doc.Paths[op.path]
+	if !ok {
+		item = &swaggerPathItem{}
+	}
+
+	var slot **swaggerOperation
+	switch op.httpMethod {
+	case "GET":
+		slot = &item.Get
+	case "PUT":
+		slot = &item.Put
+	case "POST":
+		slot = &item.Post
+	case "DELETE":
+		slot = &item.Delete
+	case "OPTIONS":
+		slot = &item.Options
+	case "HEAD":
+		slot = &item.Head
+	case "PATCH":
+		slot = &item.Patch
+	default:
+		// Swagger 2.0 cannot describe custom HTTP methods
+		return nil
+	}
+	if *slot != nil {
+		return fmt.Errorf("%s %s is already described by %s", op.httpMethod, op.path, (*slot).OperationID)
+	}
+	*slot = swaggerOp
+	doc.Paths[op.path] = item
+
+	return nil
+}
+
+func (b *schemaBuilder) swaggerOperation(op *operation) *swaggerOperation {
+	swaggerOp := &swaggerOperation{
+		Summary:     op.summary,
+		OperationID: op.id,
+		Tags:        []string{op.method.Service.GetName()},
+		Responses:   make(map[string]*swaggerResponse),
+	}
+
+	for _, p := range op.pathParams {
+		param := newSwaggerParameter(p, "path")
+		param.Required = true
+		swaggerOp.Parameters = append(swaggerOp.Parameters, param)
+	}
+	if op.body != nil {
+		body := &swaggerParameter{
+			Name:     "body",
+			In:       "body",
+			Required: true,
+			Schema:   op.body,
+		}
+		if op.method.GetClientStreaming() {
+			body.Description = requestStreamDescription
+			swaggerOp.Consumes = []string{runtime.ContentTypeNDJSON}
+		}
+		swaggerOp.Parameters = append(swaggerOp.Parameters, body)
+	}
+	for _, p := range op.queryParams {
+		swaggerOp.Parameters = append(swaggerOp.Parameters, newSwaggerParameter(p, "query"))
+	}
+
+	resp := &swaggerResponse{
+		Description: "A successful response.",
+		Schema:      op.response,
+	}
+	if op.method.GetServerStreaming() {
+		resp.Description = responseStreamDescription
+		swaggerOp.Produces = []string{runtime.ContentTypeSSE, runtime.ContentTypeNDJSON}
+	}
+	swaggerOp.Responses["200"] = resp
+	swaggerOp.Responses["default"] = &swaggerResponse{
+		Description: "An error.",
+		Schema:      &Schema{Ref: b.refPrefix + errorDefinition},
+	}
+
+	return swaggerOp
+}
+
+func newSwaggerParameter(p *parameter, in string) *swaggerParameter {
+	param := &swaggerParameter{
+		Name:        p.name,
+		In:          in,
+		Description: p.description,
+		Type:        p.schema.Type,
+		Format:      p.schema.Format,
+		Enum:        p.schema.Enum,
+	}
+	if p.repeated {
+		param.Type, param.Format, param.Enum = "array", "", nil
+		param.Items = &Schema{Type: p.schema.Type, Format: p.schema.Format, Enum: p.schema.Enum}
+		param.CollectionFormat = "multi"
+	}
+	return param
+}
+
+// errorSchema returns the schema of errors written by RESTCodec, the error message
+// along with the google.rpc.Status of the error.
+func errorSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error":   {Type: "string"},
+			"code":    {Type: "integer", Format: "int32", Description: "The gRPC status code."},
+			"message": {Type: "string"},
+			"details": {
+				Type:        "array",
+				Description: "The error details as typed JSON Any objects.",
+				Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"@type": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,39 @@
+package runtime
+
+import (
+	"context"
+)
+
+type routeKey struct{}
+
+type matchedRoute struct {
+	route  string
+	params map[string]string
+}
+
+// WithRoute returns a copy of ctx carrying the pattern route matched by
+// a router and the values of its path template variables.
+func WithRoute(ctx context.Context, route string, params map[string]string) context.Context {
+	return context.WithValue(ctx, routeKey{}, &matchedRoute{
+		route:  route,
+		params: params,
+	})
+}
+
+// RouteFromContext returns the pattern route stored in ctx by WithRoute.
+func RouteFromContext(ctx context.Context) (string, bool) {
+	m, ok := ctx.Value(routeKey{}).(*matchedRoute)
+	if !ok {
+		return "", false
+	}
+	return m.route, true
+}
+
+// PathParams returns the path template variables stored in ctx by WithRoute.
+func PathParams(ctx context.Context) map[string]string {
+	m, ok := ctx.Value(routeKey{}).(*matchedRoute)
+	if !ok {
+		return nil
+	}
+	return m.params
+}
@@ -0,0 +1,75 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		convert func(string) (interface{}, error)
+		val     string
+		want    interface{}
+		err     bool
+	}{
+		{name: "String", convert: wrap(String), val: "a b", want: "a b"},
+		{name: "Bool", convert: wrap(Bool), val: "true", want: true},
+		{name: "Bool, invalid", convert: wrap(Bool), val: "yes", err: true},
+		{name: "Float64", convert: wrap(Float64), val: "-1.5e3", want: -1500.0},
+		{name: "Float32", convert: wrap(Float32), val: "0.5", want: float32(0.5)},
+		{name: "Float32, out of range", convert: wrap(Float32), val: "1e39", err: true},
+		{name: "Int64", convert: wrap(Int64), val: "-9223372036854775808", want: int64(-9223372036854775808)},
+		{name: "Int64, hexadecimal", convert: wrap(Int64), val: "0x10", want: int64(16)},
+		{name: "Int32", convert: wrap(Int32), val: "-2147483648", want: int32(-2147483648)},
+		{name: "Int32, out of range", convert: wrap(Int32), val: "2147483648", err: true},
+		{name: "Uint64", convert: wrap(Uint64), val: "18446744073709551615", want: uint64(18446744073709551615)},
+		{name: "Uint64, negative", convert: wrap(Uint64), val: "-1", err: true},
+		{name: "Uint32", convert: wrap(Uint32), val: "4294967295", want: uint32(4294967295)},
+		{name: "Uint32, out of range", convert: wrap(Uint32), val: "4294967296", err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.convert(tc.val)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("want an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want %#v, got %#v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestConvertPointers(t *testing.T) {
+	if v, err := StringP("a"); err != nil || *v != "a" {
+		t.Errorf("StringP: got %v, %v", v, err)
+	}
+	if v, err := BoolP("false"); err != nil || *v != false {
+		t.Errorf("BoolP: got %v, %v", v, err)
+	}
+	if v, err := Int32P("7"); err != nil || *v != 7 {
+		t.Errorf("Int32P: got %v, %v", v, err)
+	}
+	if v, err := Uint64P("7"); err != nil || *v != 7 {
+		t.Errorf("Uint64P: got %v, %v", v, err)
+	}
+	if v, err := Float64P("x"); err == nil {
+		t.Errorf("Float64P: want an error, got %v", *v)
+	}
+}
+
+// wrap adapts a typed conversion function to the table of TestConvert.
+func wrap(f interface{}) func(string) (interface{}, error) {
+	fn := reflect.ValueOf(f)
+	return func(val string) (interface{}, error) {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(val)})
+		err, _ := out[1].Interface().(error)
+		return out[0].Interface(), err
+	}
+}
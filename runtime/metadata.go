@@ -0,0 +1,116 @@
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// HeaderMatcher returns the incoming metadata key an HTTP header is forwarded as,
+// ok is false if the header is not forwarded. Header names are canonical, e.g. "X-Request-Id".
+type HeaderMatcher func(header string) (key string, ok bool)
+
+// DefaultHeaderMatcher only forwards Grpc-Metadata-* headers, with the prefix removed.
+var DefaultHeaderMatcher = NewHeaderMatcher()
+
+type headerRules struct {
+	allowed  map[string]bool
+	prefixes []headerPrefix
+}
+
+type headerPrefix struct {
+	header string
+	key    string
+}
+
+type headerOption func(*headerRules)
+
+// WithAllowedHeaders forwards the headers as their names, e.g. "Authorization" as "authorization".
+func WithAllowedHeaders(headers ...string) headerOption {
+	return func(rules *headerRules) {
+		for _, h := range headers {
+			rules.allowed[textproto.CanonicalMIMEHeaderKey(h)] = true
+		}
+	}
+}
+
+// WithHeaderPrefix forwards the headers starting with prefix, with the prefix replaced by keyPrefix,
+// e.g. "X-User-Id" as "user-id" with WithHeaderPrefix("X-", "").
+func WithHeaderPrefix(prefix, keyPrefix string) headerOption {
+	return func(rules *headerRules) {
+		rules.prefixes = append(rules.prefixes, headerPrefix{
+			header: textproto.CanonicalMIMEHeaderKey(prefix),
+			key:    keyPrefix,
+		})
+	}
+}
+
+// NewHeaderMatcher returns a HeaderMatcher forwarding Grpc-Metadata-* headers with the prefix
+// removed, then the allowed headers, then the headers with the prefixes in the order they are given.
+func NewHeaderMatcher(opts ...headerOption) HeaderMatcher {
+	rules := &headerRules{
+		allowed: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(rules)
+	}
+
+	return func(header string) (string, bool) {
+		if strings.HasPrefix(header, MetadataHeaderPrefix) {
+			return strings.TrimPrefix(header, MetadataHeaderPrefix), true
+		}
+		if rules.allowed[header] {
+			return header, true
+		}
+		for _, p := range rules.prefixes {
+			if strings.HasPrefix(header, p.header) {
+				return p.key + strings.TrimPrefix(header, p.header), true
+			}
+		}
+		return "", false
+	}
+}
+
+// IncomingContext returns a copy of ctx whose incoming metadata also has the headers forwarded
+// by the matcher, with lowercased keys. Values of binary keys, ending with "-bin", are decoded
+// from base64, and dropped if they cannot be.
+func IncomingContext(ctx context.Context, header http.Header, matcher HeaderMatcher) context.Context {
+	md := metadata.MD{}
+	for name, values := range header {
+		key, ok := matcher(textproto.CanonicalMIMEHeaderKey(name))
+		if !ok || key == "" {
+			continue
+		}
+		key = strings.ToLower(key)
+
+		if strings.HasSuffix(key, "-bin") {
+			for _, v := range values {
+				if b, err := decodeBinHeader(v); err == nil {
+					md[key] = append(md[key], string(b))
+				}
+			}
+			continue
+		}
+		md[key] = append(md[key], values...)
+	}
+	if len(md) == 0 {
+		return ctx
+	}
+
+	if in, ok := metadata.FromIncomingContext(ctx); ok {
+		md = metadata.Join(in, md)
+	}
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// decodeBinHeader decodes a binary header value, which gRPC sends padded or not.
+func decodeBinHeader(v string) ([]byte, error) {
+	if len(v)%4 == 0 {
+		return base64.StdEncoding.DecodeString(v)
+	}
+	return base64.RawStdEncoding.DecodeString(v)
+}
@@ -0,0 +1,125 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHeaderMatcher(t *testing.T) {
+	matcher := NewHeaderMatcher(
+		WithAllowedHeaders("authorization", "X-Request-ID"),
+		WithHeaderPrefix("X-User-", "user-"),
+		WithHeaderPrefix("X-", ""),
+	)
+
+	for _, tc := range []struct {
+		name    string
+		matcher HeaderMatcher
+		header  string
+		key     string
+		ok      bool
+	}{
+		{name: "default, metadata", matcher: DefaultHeaderMatcher, header: "Grpc-Metadata-Session", key: "Session", ok: true},
+		{name: "default, other", matcher: DefaultHeaderMatcher, header: "Authorization"},
+		{name: "default, prefix only", matcher: DefaultHeaderMatcher, header: "Grpc-Metadata", ok: false},
+		{name: "metadata", matcher: matcher, header: "Grpc-Metadata-Session", key: "Session", ok: true},
+		{name: "allowed", matcher: matcher, header: "Authorization", key: "Authorization", ok: true},
+		{name: "allowed, canonicalized", matcher: matcher, header: "X-Request-Id", key: "X-Request-Id", ok: true},
+		{name: "first prefix", matcher: matcher, header: "X-User-Id", key: "user-Id", ok: true},
+		{name: "second prefix", matcher: matcher, header: "X-Forwarded-For", key: "Forwarded-For", ok: true},
+		{name: "not forwarded", matcher: matcher, header: "Cookie"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			key, ok := tc.matcher(tc.header)
+			if ok != tc.ok || key != tc.key {
+				t.Fatalf("want %q, %v, got %q, %v", tc.key, tc.ok, key, ok)
+			}
+		})
+	}
+}
+
+func TestIncomingContext(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		incoming metadata.MD
+		header   http.Header
+		matcher  HeaderMatcher
+		// want is nil if the context is returned as is
+		want metadata.MD
+	}{
+		{
+			name:    "metadata headers",
+			header:  http.Header{"Grpc-Metadata-Session": {"1", "2"}, "Authorization": {"token"}},
+			matcher: DefaultHeaderMatcher,
+			want:    metadata.MD{"session": {"1", "2"}},
+		},
+		{
+			name:    "allowed headers",
+			header:  http.Header{"Authorization": {"token"}, "Cookie": {"c"}},
+			matcher: NewHeaderMatcher(WithAllowedHeaders("Authorization")),
+			want:    metadata.MD{"authorization": {"token"}},
+		},
+		{
+			name:    "non-canonical header names",
+			header:  http.Header{"x-request-id": {"42"}},
+			matcher: NewHeaderMatcher(WithHeaderPrefix("X-", "")),
+			want:    metadata.MD{"request-id": {"42"}},
+		},
+		{
+			name:     "joined with the incoming metadata",
+			incoming: metadata.MD{"session": {"0"}, "user": {"bob"}},
+			header:   http.Header{"Grpc-Metadata-Session": {"1"}},
+			matcher:  DefaultHeaderMatcher,
+			want:     metadata.MD{"session": {"0", "1"}, "user": {"bob"}},
+		},
+		{
+			name: "binary values",
+			header: http.Header{
+				"Grpc-Metadata-Padded-Bin":   {"aGk="},
+				"Grpc-Metadata-Unpadded-Bin": {"aGk"},
+				"Grpc-Metadata-Invalid-Bin":  {"!!", "aGk="},
+				"Grpc-Metadata-Text":         {"aGk="},
+			},
+			matcher: DefaultHeaderMatcher,
+			want: metadata.MD{
+				"padded-bin":   {"hi"},
+				"unpadded-bin": {"hi"},
+				"invalid-bin":  {"hi"},
+				"text":         {"aGk="},
+			},
+		},
+		{
+			name:    "empty keys",
+			header:  http.Header{"X-": {"1"}},
+			matcher: NewHeaderMatcher(WithHeaderPrefix("X-", "")),
+		},
+		{
+			name:    "nothing forwarded",
+			header:  http.Header{"Authorization": {"token"}},
+			matcher: DefaultHeaderMatcher,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.incoming != nil {
+				ctx = metadata.NewIncomingContext(ctx, tc.incoming)
+			}
+
+			got := IncomingContext(ctx, tc.header, tc.matcher)
+			if tc.want == nil {
+				if got != ctx {
+					t.Fatal("want the context as is")
+				}
+				return
+			}
+			md, _ := metadata.FromIncomingContext(got)
+			if !reflect.DeepEqual(md, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, md)
+			}
+		})
+	}
+}
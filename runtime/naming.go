@@ -0,0 +1,77 @@
+package runtime
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MethodNaming returns the name a method is called by in JSON-RPC requests,
+// given its gRPC name, e.g. "/example.Example/GetPerson".
+type MethodNaming func(fullMethod string) string
+
+var (
+	// PathNaming names methods by their lowercased routes, e.g. "/example/getperson".
+	// Routers accept these names whatever naming they are given.
+	PathNaming MethodNaming = pathMethodName
+	// ServiceNaming names methods after their services, e.g. "Example.GetPerson".
+	ServiceNaming MethodNaming = serviceMethodName
+	// SnakeNaming names methods after their services in snake case, e.g. "example.get_person".
+	SnakeNaming MethodNaming = snakeMethodName
+	// PackageNaming names methods by their fully qualified names, e.g. "example.Example.GetPerson",
+	// so that services with the same name in different packages do not collide.
+	PackageNaming MethodNaming = packageMethodName
+)
+
+// splitFullMethod returns the fully qualified service name and the method name of a gRPC method.
+func splitFullMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "", fullMethod
+}
+
+// serviceName returns the name of a service without its package.
+func serviceName(service string) string {
+	return service[strings.LastIndex(service, ".")+1:]
+}
+
+func pathMethodName(fullMethod string) string {
+	service, method := splitFullMethod(fullMethod)
+	return "/" + strings.ToLower(serviceName(service)) + "/" + strings.ToLower(method)
+}
+
+func serviceMethodName(fullMethod string) string {
+	service, method := splitFullMethod(fullMethod)
+	return serviceName(service) + "." + method
+}
+
+func snakeMethodName(fullMethod string) string {
+	service, method := splitFullMethod(fullMethod)
+	return snakeCase(serviceName(service)) + "." + snakeCase(method)
+}
+
+func packageMethodName(fullMethod string) string {
+	service, method := splitFullMethod(fullMethod)
+	return service + "." + method
+}
+
+// snakeCase converts a CamelCase name to snake case, keeping acronyms together,
+// e.g. "GetHTTPStatus" to "get_http_status".
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prev != '_' && (!unicode.IsUpper(prev) || nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
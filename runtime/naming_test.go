@@ -0,0 +1,52 @@
+package runtime
+
+import "testing"
+
+func TestMethodNaming(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		naming     MethodNaming
+		fullMethod string
+		want       string
+	}{
+		{name: "path", naming: PathNaming, fullMethod: "/example.Example/GetPerson", want: "/example/getperson"},
+		{name: "path, nested package", naming: PathNaming, fullMethod: "/a.b.UserService/ListUsers", want: "/userservice/listusers"},
+		{name: "path, no package", naming: PathNaming, fullMethod: "/Example/GetPerson", want: "/example/getperson"},
+		{name: "service", naming: ServiceNaming, fullMethod: "/example.Example/GetPerson", want: "Example.GetPerson"},
+		{name: "service, no package", naming: ServiceNaming, fullMethod: "/Example/GetPerson", want: "Example.GetPerson"},
+		{name: "snake", naming: SnakeNaming, fullMethod: "/example.Example/GetPerson", want: "example.get_person"},
+		{name: "snake, acronyms", naming: SnakeNaming, fullMethod: "/a.b.HTTPService/GetHTTPStatus", want: "http_service.get_http_status"},
+		{name: "package", naming: PackageNaming, fullMethod: "/example.Example/GetPerson", want: "example.Example.GetPerson"},
+		{name: "package, no package", naming: PackageNaming, fullMethod: "/Example/GetPerson", want: "Example.GetPerson"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.naming(tc.fullMethod); got != tc.want {
+				t.Fatalf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"", ""},
+		{"Get", "get"},
+		{"GetPerson", "get_person"},
+		{"getPerson", "get_person"},
+		{"GetHTTPStatus", "get_http_status"},
+		{"HTTPStatus", "http_status"},
+		{"GetHTTP", "get_http"},
+		{"ID", "id"},
+		{"Get2Persons", "get2_persons"},
+		{"Get_Person", "get_person"},
+		{"already_snake", "already_snake"},
+		{"ÜberName", "über_name"},
+	} {
+		if got := snakeCase(tc.name); got != tc.want {
+			t.Errorf("%q: want %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
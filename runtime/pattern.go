@@ -0,0 +1,309 @@
+// Package runtime contains helpers used by the generated HTTP wrappers.
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	literal segmentKind = iota
+	wildcard
+	deepWildcard
+)
+
+type segment struct {
+	kind  segmentKind
+	value string
+	// field is an index of the variable the segment belongs to, -1 if none.
+	field int
+}
+
+// Pattern is a route built from a google.api.http rule: an HTTP method
+// followed by a path template, e.g. "GET /v1/{name=shelves/*/books/*}".
+type Pattern struct {
+	route    string
+	method   string
+	segments []segment
+	fields   []string
+	verb     string
+}
+
+// IsPattern reports whether route has the "<HTTP method> <path template>" form.
+func IsPattern(route string) bool {
+	return strings.IndexByte(route, ' ') > 0
+}
+
+// ParsePattern parses a route of the "<HTTP method> <path template>" form.
+func ParsePattern(route string) (Pattern, error) {
+	idx := strings.IndexByte(route, ' ')
+	if idx <= 0 {
+		return Pattern{}, fmt.Errorf("route %q has no HTTP method", route)
+	}
+
+	p := Pattern{
+		route:  route,
+		method: route[:idx],
+	}
+	if err := p.parseTemplate(route[idx+1:]); err != nil {
+		return Pattern{}, fmt.Errorf("route %q: %v", route, err)
+	}
+
+	return p, nil
+}
+
+// MustPattern is like ParsePattern but panics if the route cannot be parsed.
+func MustPattern(route string) Pattern {
+	p, err := ParsePattern(route)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Pattern) parseTemplate(tmpl string) error {
+	if !strings.HasPrefix(tmpl, "/") {
+		return fmt.Errorf("path template must start with /")
+	}
+	body := tmpl[1:]
+
+	if idx := strings.LastIndexByte(body, ':'); idx >= 0 &&
+		idx > strings.LastIndexByte(body, '/') && idx > strings.LastIndexByte(body, '}') {
+		p.verb = body[idx+1:]
+		body = body[:idx]
+		if p.verb == "" {
+			return fmt.Errorf("empty verb")
+		}
+	}
+	if body == "" {
+		return nil
+	}
+
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '{':
+			if depth++; depth > 1 {
+				return fmt.Errorf("nested variables are not allowed")
+			}
+		case '}':
+			if depth--; depth < 0 {
+				return fmt.Errorf("unexpected }")
+			}
+		case '/':
+			if depth == 0 {
+				parts = append(parts, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unterminated variable")
+	}
+	parts = append(parts, body[start:])
+
+	for _, part := range parts {
+		if !strings.HasPrefix(part, "{") {
+			if err := p.appendSegment(part, -1); err != nil {
+				return err
+			}
+			continue
+		}
+		if !strings.HasSuffix(part, "}") {
+			return fmt.Errorf("variable %s must be a whole segment", part)
+		}
+
+		field, sub := part[1:len(part)-1], "*"
+		if idx := strings.IndexByte(field, '='); idx >= 0 {
+			field, sub = field[:idx], field[idx+1:]
+		}
+		if err := validateFieldPath(field); err != nil {
+			return err
+		}
+		for _, f := range p.fields {
+			if f == field {
+				return fmt.Errorf("duplicate variable %s", field)
+			}
+		}
+		p.fields = append(p.fields, field)
+
+		for _, s := range strings.Split(sub, "/") {
+			if err := p.appendSegment(s, len(p.fields)-1); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, s := range p.segments {
+		if s.kind == deepWildcard && i != len(p.segments)-1 {
+			return fmt.Errorf("** must be the last segment")
+		}
+	}
+
+	return nil
+}
+
+func (p *Pattern) appendSegment(s string, field int) error {
+	seg := segment{field: field}
+	switch {
+	case s == "*":
+		seg.kind = wildcard
+	case s == "**":
+		seg.kind = deepWildcard
+	case s == "":
+		return fmt.Errorf("empty segment")
+	case strings.ContainsAny(s, "{}*="):
+		return fmt.Errorf("invalid segment %q", s)
+	default:
+		seg.kind, seg.value = literal, s
+	}
+	p.segments = append(p.segments, seg)
+
+	return nil
+}
+
+func validateFieldPath(path string) error {
+	for _, ident := range strings.Split(path, ".") {
+		if ident == "" {
+			return fmt.Errorf("invalid field path %q", path)
+		}
+		for i, c := range ident {
+			if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9' {
+				continue
+			}
+			return fmt.Errorf("invalid field path %q", path)
+		}
+	}
+	return nil
+}
+
+// String returns the route the pattern was parsed from.
+func (p Pattern) String() string {
+	return p.route
+}
+
+// Method returns the HTTP method of the pattern.
+func (p Pattern) Method() string {
+	return p.method
+}
+
+// Fields returns field paths of the path template variables in order of appearance.
+func (p Pattern) Fields() []string {
+	return p.fields
+}
+
+// Match reports whether a request with the given HTTP method and URL path
+// matches the pattern. On success it returns the values of the path template
+// variables keyed by their field paths.
+func (p Pattern) Match(method, path string) (map[string]string, bool) {
+	if method != p.method || !strings.HasPrefix(path, "/") {
+		return nil, false
+	}
+	path = path[1:]
+
+	if p.verb != "" {
+		if !strings.HasSuffix(path, ":"+p.verb) {
+			return nil, false
+		}
+		path = strings.TrimSuffix(path, ":"+p.verb)
+	}
+
+	var components []string
+	if path != "" {
+		components = strings.Split(path, "/")
+	}
+
+	values := make([][]string, len(p.fields))
+	pos := 0
+	for _, s := range p.segments {
+		var matched []string
+		switch s.kind {
+		case deepWildcard:
+			matched, pos = components[pos:], len(components)
+		default:
+			if pos >= len(components) {
+				return nil, false
+			}
+			c := components[pos]
+			if s.kind == literal && c != s.value || s.kind == wildcard && c == "" {
+				return nil, false
+			}
+			matched, pos = components[pos:pos+1], pos+1
+		}
+		if s.field >= 0 {
+			values[s.field] = append(values[s.field], matched...)
+		}
+	}
+	if pos != len(components) {
+		return nil, false
+	}
+
+	params := make(map[string]string, len(p.fields))
+	for i, field := range p.fields {
+		params[field] = strings.Join(values[i], "/")
+	}
+
+	return params, true
+}
+
+// moreSpecific reports whether p should be tried before q:
+// literal segments take precedence over wildcards, and longer paths over shorter ones.
+func (p Pattern) moreSpecific(q Pattern) bool {
+	for i := 0; i < len(p.segments) && i < len(q.segments); i++ {
+		if p.segments[i].kind != q.segments[i].kind {
+			return p.segments[i].kind < q.segments[i].kind
+		}
+	}
+	if len(p.segments) != len(q.segments) {
+		return len(p.segments) > len(q.segments)
+	}
+	if (p.verb != "") != (q.verb != "") {
+		return p.verb != ""
+	}
+	return p.route < q.route
+}
+
+// Matcher finds the pattern route matching a request.
+type Matcher struct {
+	patterns []Pattern
+}
+
+// NewMatcher builds a Matcher from routes. Routes which are not patterns
+// (see IsPattern) are skipped.
+func NewMatcher(routes []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, route := range routes {
+		if !IsPattern(route) {
+			continue
+		}
+		p, err := ParsePattern(route)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns = append(m.patterns, p)
+	}
+
+	sort.Slice(m.patterns, func(i, j int) bool {
+		return m.patterns[i].moreSpecific(m.patterns[j])
+	})
+
+	return m, nil
+}
+
+// Match returns the route of the first pattern matching the HTTP method and
+// URL path along with the values of its path template variables.
+func (m *Matcher) Match(method, path string) (route string, params map[string]string, ok bool) {
+	for _, p := range m.patterns {
+		if params, ok := p.Match(method, path); ok {
+			return p.route, params, true
+		}
+	}
+	return "", nil, false
+}
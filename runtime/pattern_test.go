@@ -0,0 +1,189 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	for _, tc := range []struct {
+		route  string
+		fields []string
+		err    bool
+	}{
+		{route: "GET /v1/people"},
+		{route: "GET /"},
+		{route: "GET /v1/{name}", fields: []string{"name"}},
+		{route: "GET /v1/{name=shelves/*/books/*}", fields: []string{"name"}},
+		{route: "GET /v1/{parent.id}/books/{book_id}", fields: []string{"parent.id", "book_id"}},
+		{route: "GET /v1/{name=files/**}", fields: []string{"name"}},
+		{route: "POST /v1/{name=people/*}:undelete", fields: []string{"name"}},
+		{route: "POST /v1/people:batchGet"},
+		{route: "/v1/people", err: true},
+		{route: "GET v1/people", err: true},
+		{route: "GET /v1//people", err: true},
+		{route: "GET /v1/{name", err: true},
+		{route: "GET /v1/name}", err: true},
+		{route: "GET /v1/{a={b}}", err: true},
+		{route: "GET /v1/x{name}", err: true},
+		{route: "GET /v1/{name}/{name}", err: true},
+		{route: "GET /v1/{1name}", err: true},
+		{route: "GET /v1/{name=**}/books", err: true},
+		{route: "GET /v1/people:", err: true},
+	} {
+		t.Run(tc.route, func(t *testing.T) {
+			p, err := ParsePattern(tc.route)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("want an error, got %+v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.String() != tc.route {
+				t.Errorf("want route %q, got %q", tc.route, p.String())
+			}
+			if !reflect.DeepEqual(p.Fields(), tc.fields) {
+				t.Errorf("want fields %q, got %q", tc.fields, p.Fields())
+			}
+		})
+	}
+}
+
+func TestPatternMatch(t *testing.T) {
+	for _, tc := range []struct {
+		route  string
+		method string
+		path   string
+		// params is nil if the path does not match
+		params map[string]string
+	}{
+		{
+			route: "GET /v1/people", method: "GET", path: "/v1/people",
+			params: map[string]string{},
+		},
+		{
+			route: "GET /v1/people", method: "POST", path: "/v1/people",
+		},
+		{
+			route: "GET /v1/people", method: "GET", path: "/v1/people/1",
+		},
+		{
+			route: "GET /v1/people", method: "GET", path: "v1/people",
+		},
+		{
+			route: "GET /v1/people/{name}", method: "GET", path: "/v1/people/bob",
+			params: map[string]string{"name": "bob"},
+		},
+		{
+			route: "GET /v1/people/{name}", method: "GET", path: "/v1/people/",
+		},
+		{
+			route: "GET /v1/{name=shelves/*/books/*}", method: "GET", path: "/v1/shelves/1/books/2",
+			params: map[string]string{"name": "shelves/1/books/2"},
+		},
+		{
+			route: "GET /v1/{name=shelves/*/books/*}", method: "GET", path: "/v1/shelves/1/authors/2",
+		},
+		{
+			route: "GET /v1/{parent.id}/books/{book_id}", method: "GET", path: "/v1/1/books/2",
+			params: map[string]string{"parent.id": "1", "book_id": "2"},
+		},
+		{
+			route: "GET /v1/{name=files/**}", method: "GET", path: "/v1/files/a/b/c.txt",
+			params: map[string]string{"name": "files/a/b/c.txt"},
+		},
+		{
+			route: "GET /v1/{name=files/**}", method: "GET", path: "/v1/files",
+			params: map[string]string{"name": "files"},
+		},
+		{
+			route: "GET /v1/{name=**}", method: "GET", path: "/v1/a/b",
+			params: map[string]string{"name": "a/b"},
+		},
+		{
+			route: "POST /v1/{name=people/*}:undelete", method: "POST", path: "/v1/people/bob:undelete",
+			params: map[string]string{"name": "people/bob"},
+		},
+		{
+			route: "POST /v1/{name=people/*}:undelete", method: "POST", path: "/v1/people/bob",
+		},
+		{
+			route: "POST /v1/{name=people/*}:undelete", method: "POST", path: "/v1/people/bob:delete",
+		},
+		{
+			route: "POST /v1/people:batchGet", method: "POST", path: "/v1/people:batchGet",
+			params: map[string]string{},
+		},
+		{
+			route: "HEAD /v1/people", method: "HEAD", path: "/v1/people",
+			params: map[string]string{},
+		},
+	} {
+		t.Run(tc.method+" "+tc.path+" against "+tc.route, func(t *testing.T) {
+			params, ok := MustPattern(tc.route).Match(tc.method, tc.path)
+			if ok != (tc.params != nil) {
+				t.Fatalf("want match %v, got %v", tc.params != nil, ok)
+			}
+			if ok && !reflect.DeepEqual(params, tc.params) {
+				t.Fatalf("want params %v, got %v", tc.params, params)
+			}
+		})
+	}
+}
+
+func TestMatcherPrecedence(t *testing.T) {
+	m, err := NewMatcher([]string{
+		"/example/getperson",
+		"GET /v1/{name=**}",
+		"GET /v1/{name}",
+		"GET /v1/people/{name}",
+		"GET /v1/people/me",
+		"GET /v1/people/{name}:count",
+		"POST /v1/people/{name}",
+		"DELETE /v1/people/{name=*}/{field}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		// route is empty if no route matches
+		route  string
+		params map[string]string
+	}{
+		{"GET", "/v1/people/me", "GET /v1/people/me", map[string]string{}},
+		{"GET", "/v1/people/bob", "GET /v1/people/{name}", map[string]string{"name": "bob"}},
+		{"GET", "/v1/people/bob:count", "GET /v1/people/{name}:count", map[string]string{"name": "bob"}},
+		{"GET", "/v1/people", "GET /v1/{name}", map[string]string{"name": "people"}},
+		{"GET", "/v1/books/1/pages", "GET /v1/{name=**}", map[string]string{"name": "books/1/pages"}},
+		{"POST", "/v1/people/bob", "POST /v1/people/{name}", map[string]string{"name": "bob"}},
+		{"DELETE", "/v1/people/bob/age", "DELETE /v1/people/{name=*}/{field}", map[string]string{"name": "bob", "field": "age"}},
+		{"PUT", "/v1/people/bob", "", nil},
+		{"DELETE", "/v1/people/bob", "", nil},
+		{"POST", "/example/getperson", "", nil},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			route, params, ok := m.Match(tc.method, tc.path)
+			if ok != (tc.route != "") {
+				t.Fatalf("want match %v, got %v with %q", tc.route != "", ok, route)
+			}
+			if route != tc.route {
+				t.Fatalf("want route %q, got %q", tc.route, route)
+			}
+			if ok && !reflect.DeepEqual(params, tc.params) {
+				t.Fatalf("want params %v, got %v", tc.params, params)
+			}
+		})
+	}
+}
+
+func TestNewMatcherInvalidPattern(t *testing.T) {
+	if _, err := NewMatcher([]string{"GET /v1/{name"}); err == nil {
+		t.Fatal("want an error")
+	}
+}
@@ -0,0 +1,301 @@
+package runtime
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+)
+
+// Filter is a set of field paths which must not be populated from query parameters,
+// e.g. because they are bound to path template variables or to the request body.
+type Filter struct {
+	paths [][]string
+}
+
+// NewFilter returns a Filter of the given dot-separated field paths.
+func NewFilter(paths ...string) *Filter {
+	f := &Filter{}
+	for _, path := range paths {
+		f.paths = append(f.paths, strings.Split(path, "."))
+	}
+	return f
+}
+
+// HasCommonPrefix reports whether fieldPath is a prefix of a path in the filter
+// or has a path in the filter as its prefix.
+func (f *Filter) HasCommonPrefix(fieldPath []string) bool {
+	if f == nil {
+		return false
+	}
+	for _, path := range f.paths {
+		n := len(path)
+		if len(fieldPath) < n {
+			n = len(fieldPath)
+		}
+		common := true
+		for i := 0; i < n; i++ {
+			if path[i] != fieldPath[i] {
+				common = false
+				break
+			}
+		}
+		if common {
+			return true
+		}
+	}
+	return false
+}
+
+// PopulateQueryParameters populates "values" into "msg".
+// A value is ignored if its key starts with a field path in "filter".
+// Nested fields are addressed with dotted names, e.g. "filter.owner.id",
+// and repeated fields with repeated keys, e.g. "id=1&id=2".
+func PopulateQueryParameters(msg proto.Message, values url.Values, filter *Filter) error {
+	for key, values := range values {
+		fieldPath := strings.Split(key, ".")
+		if filter.HasCommonPrefix(fieldPath) {
+			continue
+		}
+		if err := populateFieldValueFromPath(msg, fieldPath, values); err != nil {
+			return fmt.Errorf("query parameter %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// PopulateFieldFromPath sets a value in a nested Protobuf structure.
+// It instantiates missing protobuf fields as it goes.
+func PopulateFieldFromPath(msg proto.Message, fieldPathString string, value string) error {
+	fieldPath := strings.Split(fieldPathString, ".")
+	return populateFieldValueFromPath(msg, fieldPath, []string{value})
+}
+
+func populateFieldValueFromPath(msg proto.Message, fieldPath []string, values []string) error {
+	m := reflect.ValueOf(msg)
+	if m.Kind() != reflect.Ptr || m.IsNil() {
+		return fmt.Errorf("unexpected type %T: %v", msg, msg)
+	}
+
+	var props *proto.Properties
+	m = m.Elem()
+	for i, fieldName := range fieldPath {
+		isLast := i == len(fieldPath)-1
+		if !isLast && m.Kind() != reflect.Struct {
+			return fmt.Errorf("non-aggregate type in the mid of path: %s", strings.Join(fieldPath, "."))
+		}
+
+		var (
+			f   reflect.Value
+			err error
+		)
+		f, props, err = fieldByProtoName(m, fieldName)
+		if err != nil {
+			return err
+		}
+		if !f.IsValid() {
+			// unknown fields are ignored like unknown JSON fields are
+			return nil
+		}
+
+		if !isLast {
+			switch f.Kind() {
+			case reflect.Ptr:
+				if f.IsNil() {
+					f.Set(reflect.New(f.Type().Elem()))
+				}
+				m = f.Elem()
+				continue
+			case reflect.Struct:
+				m = f
+				continue
+			default:
+				return fmt.Errorf("non-aggregate type in the mid of path: %s", strings.Join(fieldPath, "."))
+			}
+		}
+		m = f
+	}
+
+	if len(values) == 0 {
+		return fmt.Errorf("no value of field: %s", strings.Join(fieldPath, "."))
+	}
+	switch m.Kind() {
+	case reflect.Slice:
+		if m.Type().Elem().Kind() != reflect.Uint8 {
+			return populateRepeatedField(m, values, props)
+		}
+	case reflect.Map:
+		return fmt.Errorf("unsupported map field: %s", strings.Join(fieldPath, "."))
+	}
+	if len(values) > 1 {
+		return fmt.Errorf("too many values of field: %s", strings.Join(fieldPath, "."))
+	}
+
+	return populateField(m, values[0], props)
+}
+
+// fieldByProtoName looks up a field whose corresponding protobuf field name is "name".
+// "m" must be a struct value. It returns zero reflect.Value if no such field found.
+func fieldByProtoName(m reflect.Value, name string) (reflect.Value, *proto.Properties, error) {
+	props := proto.GetProperties(m.Type())
+
+	// look up field name in oneof map
+	for _, op := range props.OneofTypes {
+		if name == op.Prop.OrigName || name == op.Prop.JSONName {
+			field := m.Field(op.Field)
+			if !field.IsNil() {
+				if field.Elem().Elem().Type() == op.Type.Elem() {
+					return field.Elem().Elem().Field(0), op.Prop, nil
+				}
+				return reflect.Value{}, nil, fmt.Errorf("field already set for %s oneof", props.Prop[op.Field].OrigName)
+			}
+			v := reflect.New(op.Type.Elem())
+			field.Set(v)
+			return v.Elem().Field(0), op.Prop, nil
+		}
+	}
+
+	for _, p := range props.Prop {
+		if p.OrigName == name || p.JSONName == name {
+			return m.FieldByName(p.Name), p, nil
+		}
+	}
+
+	return reflect.Value{}, nil, nil
+}
+
+func populateRepeatedField(f reflect.Value, values []string, props *proto.Properties) error {
+	f.Set(reflect.MakeSlice(f.Type(), len(values), len(values)))
+	for i, value := range values {
+		if err := populateField(f.Index(i), value, props); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func populateField(f reflect.Value, value string, props *proto.Properties) error {
+	// well-known types are populated from their proto3 JSON representation
+	switch f.Type() {
+	case timestampType:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		ts, err := ptypes.TimestampProto(t)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(ts))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(ptypes.DurationProto(d)))
+		return nil
+	}
+	if wrapperTypes[f.Type()] {
+		v := reflect.New(f.Type().Elem())
+		if err := populateField(v.Elem().FieldByName("Value"), value, nil); err != nil {
+			return err
+		}
+		f.Set(v)
+		return nil
+	}
+
+	if f.Kind() == reflect.Ptr && f.Type().Elem().Kind() != reflect.Struct {
+		v := reflect.New(f.Type().Elem())
+		if err := populateField(v.Elem(), value, props); err != nil {
+			return err
+		}
+		f.Set(v)
+		return nil
+	}
+
+	if props != nil && props.Enum != "" {
+		return populateEnum(f, value, props.Enum)
+	}
+
+	switch f.Kind() {
+	case reflect.Slice:
+		if f.Type().Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		b, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			if b, err = base64.URLEncoding.DecodeString(value); err != nil {
+				return err
+			}
+		}
+		f.SetBytes(b)
+		return nil
+	}
+
+	conv, ok := convFromKind[f.Kind()]
+	if !ok {
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	result := conv.Call([]reflect.Value{reflect.ValueOf(value)})
+	if err := result[1].Interface(); err != nil {
+		return err.(error)
+	}
+	f.Set(result[0].Convert(f.Type()))
+
+	return nil
+}
+
+// populateEnum sets an enum field either by the name of the value or by its number.
+func populateEnum(f reflect.Value, value, enumName string) error {
+	if f.Kind() != reflect.Int32 {
+		return fmt.Errorf("unsupported enum field type %s", f.Type())
+	}
+	if v, ok := proto.EnumValueMap(enumName)[value]; ok {
+		f.SetInt(int64(v))
+		return nil
+	}
+	v, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid value of %s", value, enumName)
+	}
+	f.SetInt(v)
+
+	return nil
+}
+
+var (
+	timestampType = reflect.TypeOf(&timestamp.Timestamp{})
+	durationType  = reflect.TypeOf(&duration.Duration{})
+	wrapperTypes  = map[reflect.Type]bool{
+		reflect.TypeOf(&wrappers.DoubleValue{}): true,
+		reflect.TypeOf(&wrappers.FloatValue{}):  true,
+		reflect.TypeOf(&wrappers.Int64Value{}):  true,
+		reflect.TypeOf(&wrappers.UInt64Value{}): true,
+		reflect.TypeOf(&wrappers.Int32Value{}):  true,
+		reflect.TypeOf(&wrappers.UInt32Value{}): true,
+		reflect.TypeOf(&wrappers.BoolValue{}):   true,
+		reflect.TypeOf(&wrappers.StringValue{}): true,
+		reflect.TypeOf(&wrappers.BytesValue{}):  true,
+	}
+
+	convFromKind = map[reflect.Kind]reflect.Value{
+		reflect.String:  reflect.ValueOf(String),
+		reflect.Bool:    reflect.ValueOf(Bool),
+		reflect.Float64: reflect.ValueOf(Float64),
+		reflect.Float32: reflect.ValueOf(Float32),
+		reflect.Int64:   reflect.ValueOf(Int64),
+		reflect.Int32:   reflect.ValueOf(Int32),
+		reflect.Uint64:  reflect.ValueOf(Uint64),
+		reflect.Uint32:  reflect.ValueOf(Uint32),
+	}
+)
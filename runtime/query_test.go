@@ -0,0 +1,312 @@
+package runtime
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+)
+
+// queryStatus is a proto3 enum, registered as runtime.QueryStatus.
+type queryStatus int32
+
+const (
+	queryStatusUnknown  queryStatus = 0
+	queryStatusActive   queryStatus = 1
+	queryStatusArchived queryStatus = 2
+)
+
+func init() {
+	proto.RegisterEnum("runtime.QueryStatus", map[int32]string{
+		0: "UNKNOWN",
+		1: "ACTIVE",
+		2: "ARCHIVED",
+	}, map[string]int32{
+		"UNKNOWN":  0,
+		"ACTIVE":   1,
+		"ARCHIVED": 2,
+	})
+}
+
+// queryMessage is a proto3 message with a field of every kind populated from query parameters,
+// as protoc-gen-go generates it.
+type queryMessage struct {
+	Name      string                   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	PageSize  int32                    `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Offset    int64                    `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit     uint32                   `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Score     float64                  `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	Ratio     float32                  `protobuf:"fixed32,6,opt,name=ratio,proto3" json:"ratio,omitempty"`
+	Enabled   bool                     `protobuf:"varint,7,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Data      []byte                   `protobuf:"bytes,8,opt,name=data,proto3" json:"data,omitempty"`
+	Ids       []int64                  `protobuf:"varint,9,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	Tags      []string                 `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty"`
+	Status    queryStatus              `protobuf:"varint,11,opt,name=status,proto3,enum=runtime.QueryStatus" json:"status,omitempty"`
+	Statuses  []queryStatus            `protobuf:"varint,12,rep,packed,name=statuses,proto3,enum=runtime.QueryStatus" json:"statuses,omitempty"`
+	Since     *timestamp.Timestamp     `protobuf:"bytes,13,opt,name=since,proto3" json:"since,omitempty"`
+	Timeout   *duration.Duration       `protobuf:"bytes,14,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Label     *wrappers.StringValue    `protobuf:"bytes,15,opt,name=label,proto3" json:"label,omitempty"`
+	MaxSize   *wrappers.Int64Value     `protobuf:"bytes,16,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"`
+	Visible   *wrappers.BoolValue      `protobuf:"bytes,17,opt,name=visible,proto3" json:"visible,omitempty"`
+	Nested    *queryMessage            `protobuf:"bytes,18,opt,name=nested,proto3" json:"nested,omitempty"`
+	Labels    map[string]string        `protobuf:"bytes,19,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Selection isQueryMessage_Selection `protobuf_oneof:"selection"`
+}
+
+func (m *queryMessage) Reset()         { *m = queryMessage{} }
+func (m *queryMessage) String() string { return proto.CompactTextString(m) }
+func (*queryMessage) ProtoMessage()    {}
+
+func (*queryMessage) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*queryMessage_ByName)(nil),
+		(*queryMessage_ByNumber)(nil),
+	}
+}
+
+type isQueryMessage_Selection interface {
+	isQueryMessage_Selection()
+}
+
+type queryMessage_ByName struct {
+	ByName string `protobuf:"bytes,20,opt,name=by_name,json=byName,proto3,oneof"`
+}
+
+type queryMessage_ByNumber struct {
+	ByNumber int32 `protobuf:"varint,21,opt,name=by_number,json=byNumber,proto3,oneof"`
+}
+
+func (*queryMessage_ByName) isQueryMessage_Selection()   {}
+func (*queryMessage_ByNumber) isQueryMessage_Selection() {}
+
+func mustTimestamp(t *testing.T, s string) *timestamp.Timestamp {
+	tm, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := ptypes.TimestampProto(tm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestPopulateQueryParameters(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		query  string
+		filter *Filter
+		want   *queryMessage
+	}{
+		{
+			name:  "scalars",
+			query: "name=bob&offset=-12&limit=10&score=1.5&ratio=0.25&enabled=true",
+			want: &queryMessage{
+				Name:    "bob",
+				Offset:  -12,
+				Limit:   10,
+				Score:   1.5,
+				Ratio:   0.25,
+				Enabled: true,
+			},
+		},
+		{
+			name:  "JSON name",
+			query: "pageSize=20",
+			want:  &queryMessage{PageSize: 20},
+		},
+		{
+			name:  ".proto name",
+			query: "page_size=20",
+			want:  &queryMessage{PageSize: 20},
+		},
+		{
+			name:  "bytes",
+			query: "data=" + url.QueryEscape("aGVsbG8="),
+			want:  &queryMessage{Data: []byte("hello")},
+		},
+		{
+			name:  "URL-safe bytes",
+			query: "data=__8=",
+			want:  &queryMessage{Data: []byte{0xff, 0xff}},
+		},
+		{
+			name:  "repeated fields",
+			query: "ids=1&ids=2&ids=3&tags=a&tags=b",
+			want:  &queryMessage{Ids: []int64{1, 2, 3}, Tags: []string{"a", "b"}},
+		},
+		{
+			name:  "enum by name",
+			query: "status=ACTIVE",
+			want:  &queryMessage{Status: queryStatusActive},
+		},
+		{
+			name:  "enum by number",
+			query: "status=2",
+			want:  &queryMessage{Status: queryStatusArchived},
+		},
+		{
+			name:  "repeated enum by name or number",
+			query: "statuses=ARCHIVED&statuses=1&statuses=UNKNOWN",
+			want:  &queryMessage{Statuses: []queryStatus{queryStatusArchived, queryStatusActive, queryStatusUnknown}},
+		},
+		{
+			name:  "well-known types",
+			query: "since=2017-01-02T03:04:05.5Z&timeout=1.5s&label=x&maxSize=100&visible=false",
+			want: &queryMessage{
+				Since:   mustTimestamp(t, "2017-01-02T03:04:05.5Z"),
+				Timeout: ptypes.DurationProto(1500 * time.Millisecond),
+				Label:   &wrappers.StringValue{Value: "x"},
+				MaxSize: &wrappers.Int64Value{Value: 100},
+				Visible: &wrappers.BoolValue{Value: false},
+			},
+		},
+		{
+			name:  "nested fields",
+			query: "nested.name=alice&nested.nested.page_size=3&nested.ids=4",
+			want: &queryMessage{
+				Nested: &queryMessage{
+					Name:   "alice",
+					Ids:    []int64{4},
+					Nested: &queryMessage{PageSize: 3},
+				},
+			},
+		},
+		{
+			name:  "oneof",
+			query: "byName=bob",
+			want:  &queryMessage{Selection: &queryMessage_ByName{ByName: "bob"}},
+		},
+		{
+			name:  "oneof by .proto name",
+			query: "by_number=7",
+			want:  &queryMessage{Selection: &queryMessage_ByNumber{ByNumber: 7}},
+		},
+		{
+			name:  "unknown parameters",
+			query: "name=bob&page=2&nested.unknown=1",
+			want:  &queryMessage{Name: "bob", Nested: &queryMessage{}},
+		},
+		{
+			name:   "filtered fields",
+			query:  "name=bob&offset=1&nested.name=alice&nested.offset=2",
+			filter: NewFilter("name", "nested.name"),
+			want:   &queryMessage{Offset: 1, Nested: &queryMessage{Offset: 2}},
+		},
+		{
+			name:   "filtered message",
+			query:  "name=bob&nested.name=alice&nested.nested.offset=2",
+			filter: NewFilter("nested"),
+			want:   &queryMessage{Name: "bob"},
+		},
+		{
+			name:   "filtered nested field of the parameter",
+			query:  "name=bob&nested=1",
+			filter: NewFilter("nested.name"),
+			want:   &queryMessage{Name: "bob"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := &queryMessage{}
+			if err := PopulateQueryParameters(got, values, tc.filter); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPopulateQueryParametersErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		query string
+	}{
+		{name: "too many values", query: "name=a&name=b"},
+		{name: "invalid integer", query: "offset=x"},
+		{name: "integer overflow", query: "pageSize=3000000000"},
+		{name: "negative unsigned", query: "limit=-1"},
+		{name: "invalid bool", query: "enabled=maybe"},
+		{name: "invalid bytes", query: "data=!!"},
+		{name: "invalid enum name", query: "status=DELETED"},
+		{name: "invalid repeated value", query: "ids=1&ids=x"},
+		{name: "invalid timestamp", query: "since=yesterday"},
+		{name: "invalid duration", query: "timeout=1.5"},
+		{name: "invalid wrapper value", query: "maxSize=big"},
+		{name: "map field", query: "labels=a"},
+		{name: "path through a scalar", query: "name.first=bob"},
+		{name: "two fields of a oneof", query: "byName=bob&byNumber=1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg := &queryMessage{}
+			if err := PopulateQueryParameters(msg, values, nil); err == nil {
+				t.Fatalf("want an error, got %v", msg)
+			}
+		})
+	}
+}
+
+func TestPopulateFieldFromPath(t *testing.T) {
+	got := &queryMessage{}
+	if err := PopulateFieldFromPath(got, "nested.nested.status", "ACTIVE"); err != nil {
+		t.Fatal(err)
+	}
+	if err := PopulateFieldFromPath(got, "name", "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &queryMessage{
+		Name:   "bob",
+		Nested: &queryMessage{Nested: &queryMessage{Status: queryStatusActive}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestFilterHasCommonPrefix(t *testing.T) {
+	f := NewFilter("name", "parent.id")
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"name", true},
+		{"name.first", true},
+		{"parent", true},
+		{"parent.id", true},
+		{"parent.id.value", true},
+		{"parent.name", false},
+		{"names", false},
+		{"id", false},
+	} {
+		if got := f.HasCommonPrefix(splitPath(tc.path)); got != tc.want {
+			t.Errorf("%s: want %v, got %v", tc.path, tc.want, got)
+		}
+	}
+
+	var none *Filter
+	if none.HasCommonPrefix([]string{"name"}) {
+		t.Error("nil filter: want false, got true")
+	}
+}
+
+func splitPath(path string) []string {
+	return NewFilter(path).paths[0]
+}
@@ -0,0 +1,398 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// StreamCodec is the part of codec.Codec used by streams to encode and decode messages.
+type StreamCodec interface {
+	ReadRequest(r *http.Request, out interface{}) error
+	WriteResponse(w http.ResponseWriter, resp interface{}) error
+	WriteError(w http.ResponseWriter, err error) error
+}
+
+// ClientStreamChecker is implemented by codecs which cannot read the request messages of client
+// and bidirectional streams, e.g. because the request body is a single call as with JSON-RPC.
+type ClientStreamChecker interface {
+	// CheckClientStream returns the error the request of a client stream is answered with.
+	CheckClientStream(r *http.Request) error
+}
+
+// CheckClientStream returns the error of the codec if it is a ClientStreamChecker, nil otherwise.
+func CheckClientStream(cdc StreamCodec, r *http.Request) error {
+	if checker, ok := cdc.(ClientStreamChecker); ok {
+		return checker.CheckClientStream(r)
+	}
+	return nil
+}
+
+// MetadataHeaderPrefix is prepended to the keys of gRPC metadata sent as HTTP headers.
+const MetadataHeaderPrefix = "Grpc-Metadata-"
+
+// Content types of streamed responses.
+const (
+	ContentTypeSSE    = "text/event-stream"
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+// ServerStream implements grpc.ServerStream on top of an HTTP request and response.
+// Messages are encoded and decoded with the codec.
+type ServerStream struct {
+	ctx     context.Context
+	r       *http.Request
+	w       http.ResponseWriter
+	flusher http.Flusher
+	cdc     StreamCodec
+	// framer is nil if the single response message is written as is.
+	framer framer
+	// body is nil if request messages are not read from the request body.
+	body *bufio.Reader
+	// resp is the single response message, written when the stream finishes.
+	resp interface{}
+
+	// transport holds the metadata of the stream, it is carried by the context.
+	transport  *ServerTransportStream
+	headerSent bool
+}
+
+// NewServerStream returns a stream for a server-streaming method, named by its full method
+// name, e.g. "/example.Example/ListPeople". Every message sent is written as a Server-Sent Event,
+// or as a line of newline-delimited JSON if the Accept header of r prefers application/x-ndjson.
+// The stream is bound to the context of r, so it is canceled when the client disconnects.
+func NewServerStream(w http.ResponseWriter, r *http.Request, cdc StreamCodec, method string) (*ServerStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "streaming is not supported by the response writer")
+	}
+
+	var f framer = sseFramer{}
+	if negotiateStreamType(r.Header.Get("Accept")) == ContentTypeNDJSON {
+		f = ndjsonFramer{}
+	}
+
+	return newServerStream(&ServerStream{
+		r:       r,
+		w:       w,
+		flusher: flusher,
+		cdc:     cdc,
+		framer:  f,
+	}, method), nil
+}
+
+// NewClientStream returns a stream for a client-streaming method, named by its full method name.
+// Request messages are read from the lines of the request body, which is expected to be
+// newline-delimited JSON. The single response message is written with the codec as is
+// when the stream finishes. It fails if the codec is a ClientStreamChecker rejecting the request.
+func NewClientStream(w http.ResponseWriter, r *http.Request, cdc StreamCodec, method string) (*ServerStream, error) {
+	if err := CheckClientStream(cdc, r); err != nil {
+		return nil, err
+	}
+
+	return newServerStream(&ServerStream{
+		r:    r,
+		w:    w,
+		cdc:  cdc,
+		body: bufio.NewReader(r.Body),
+	}, method), nil
+}
+
+// newServerStream binds the stream to the context of its request, which carries the transport
+// of the stream, so that grpc.SetHeader, grpc.SendHeader and grpc.SetTrailer work with it.
+func newServerStream(s *ServerStream, method string) *ServerStream {
+	s.transport = NewStreamTransportStream(method, func() error {
+		s.writeHeader()
+		return nil
+	})
+	s.ctx = grpc.NewContextWithServerTransportStream(s.r.Context(), s.transport)
+	return s
+}
+
+// negotiateStreamType returns the streamed content type the Accept header prefers: the one
+// with the highest quality, the first listed on a tie. Media ranges with q=0 are not acceptable.
+// It returns text/event-stream if neither of the types is acceptable.
+func negotiateStreamType(accept string) string {
+	var (
+		best          = ContentTypeSSE
+		bestQ, bestAt = 0.0, 0
+	)
+	for _, contentType := range []string{ContentTypeSSE, ContentTypeNDJSON} {
+		q, at := acceptQuality(accept, contentType)
+		if q > bestQ || q == bestQ && q > 0 && at < bestAt {
+			best, bestQ, bestAt = contentType, q, at
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality the Accept header gives to the content type, by the most
+// specific media range matching it, and the position of that range. It is 0 if none matches.
+func acceptQuality(accept, contentType string) (q float64, at int) {
+	specificity := 0
+	for i, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		var s int
+		switch {
+		case mediaType == contentType:
+			s = 3
+		case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(mediaType, "*")):
+			s = 2
+		case mediaType == "*/*":
+			s = 1
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+
+		rangeQ := 1.0
+		if v, ok := params["q"]; ok {
+			if rangeQ, err = strconv.ParseFloat(v, 64); err != nil || rangeQ < 0 || rangeQ > 1 {
+				continue
+			}
+		}
+		specificity, q, at = s, rangeQ, i
+	}
+	return q, at
+}
+
+// Context returns the context of the HTTP request.
+func (s *ServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// SetHeader sets the header metadata. It may be called multiple times.
+// It fails if called after the header has been sent.
+func (s *ServerStream) SetHeader(md metadata.MD) error {
+	return s.transport.SetHeader(md)
+}
+
+// SendHeader sends the header metadata along with the HTTP response headers.
+func (s *ServerStream) SendHeader(md metadata.MD) error {
+	return s.transport.SendHeader(md)
+}
+
+// SetTrailer sets the trailer metadata which is sent as Grpc-Trailer-* HTTP trailers
+// when the stream finishes.
+func (s *ServerStream) SetTrailer(md metadata.MD) {
+	s.transport.SetTrailer(md)
+}
+
+// SendMsg encodes m with the codec and sends it.
+func (s *ServerStream) SendMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	if s.framer == nil {
+		if s.resp != nil {
+			return errors.New("response has already been sent")
+		}
+		s.resp = m
+		return nil
+	}
+
+	data, err := EncodeMessage(s.cdc, m)
+	if err != nil {
+		return err
+	}
+
+	s.writeHeader()
+	if err := s.framer.writeMessage(s.w, data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+
+	return nil
+}
+
+// RecvMsg decodes the next line of the request body into m with the codec.
+// It returns io.EOF when the body is over, and always for server-streaming
+// methods, whose request message is read before the stream is created.
+func (s *ServerStream) RecvMsg(m interface{}) error {
+	if s.body == nil {
+		return io.EOF
+	}
+	if err := s.ctx.Err(); err != nil {
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	for {
+		line, err := s.body.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		return DecodeMessage(s.cdc, s.r.WithContext(s.ctx), line, m)
+	}
+}
+
+// Finish completes the stream with the error returned by the server method
+// and sends the trailer metadata. An error occurred before anything has been sent
+// is written with the codec as an ordinary response, later ones are framed like messages.
+func (s *ServerStream) Finish(err error) {
+	if s.framer != nil && (s.headerSent || err == nil) {
+		s.writeHeader()
+		if err != nil {
+			s.framer.writeError(s.w, EncodeError(s.cdc, err))
+		}
+		s.transport.WriteTrailer(s.w)
+		s.flusher.Flush()
+		return
+	}
+
+	// nothing has been streamed, the response is written with the codec as usual
+	if !s.headerSent {
+		s.headerSent = true
+		s.transport.WriteHeader(s.w)
+	}
+	s.transport.WriteTrailer(s.w)
+	if err != nil {
+		s.cdc.WriteError(s.w, err)
+		return
+	}
+	if s.resp != nil {
+		s.cdc.WriteResponse(s.w, s.resp)
+	}
+}
+
+func (s *ServerStream) writeHeader() {
+	if s.headerSent {
+		return
+	}
+	s.headerSent = true
+
+	s.transport.WriteHeader(s.w)
+	if s.framer == nil {
+		return
+	}
+
+	h := s.w.Header()
+	h.Set("Content-Type", s.framer.contentType())
+	h.Set("Cache-Control", "no-cache")
+	s.w.WriteHeader(http.StatusOK)
+	s.flusher.Flush()
+}
+
+// framer frames encoded messages of a streamed response.
+type framer interface {
+	contentType() string
+	writeMessage(w io.Writer, data []byte) error
+	writeError(w io.Writer, data []byte) error
+}
+
+// sseFramer writes messages as Server-Sent Events and errors as "error" events.
+type sseFramer struct{}
+
+func (sseFramer) contentType() string {
+	return ContentTypeSSE
+}
+
+func (sseFramer) writeMessage(w io.Writer, data []byte) error {
+	return writeEvent(w, "", data)
+}
+
+func (sseFramer) writeError(w io.Writer, data []byte) error {
+	return writeEvent(w, "error", data)
+}
+
+// writeEvent writes a Server-Sent Event, prefixing every line of data with "data:".
+func writeEvent(w io.Writer, event string, data []byte) error {
+	var buf bytes.Buffer
+	if event != "" {
+		buf.WriteString("event: " + event + "\n")
+	}
+	for _, line := range bytes.Split(bytes.TrimRight(data, "\r\n"), []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(bytes.TrimRight(line, "\r"))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ndjsonFramer writes messages and errors as lines of newline-delimited JSON.
+type ndjsonFramer struct{}
+
+func (ndjsonFramer) contentType() string {
+	return ContentTypeNDJSON
+}
+
+func (ndjsonFramer) writeMessage(w io.Writer, data []byte) error {
+	// newlines can only be insignificant whitespace in JSON
+	line := bytes.Replace(bytes.TrimRight(data, "\r\n"), []byte("\n"), []byte(" "), -1)
+	_, err := w.Write(append(line, '\n'))
+	return err
+}
+
+func (f ndjsonFramer) writeError(w io.Writer, data []byte) error {
+	return f.writeMessage(w, data)
+}
+
+// EncodeMessage returns m as the codec writes it in a response.
+func EncodeMessage(cdc StreamCodec, m interface{}) ([]byte, error) {
+	buf := newBufferedWriter()
+	if err := cdc.WriteResponse(buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeError returns err as the codec writes it in a response.
+func EncodeError(cdc StreamCodec, err error) []byte {
+	buf := newBufferedWriter()
+	cdc.WriteError(buf, err)
+	return buf.Bytes()
+}
+
+// DecodeMessage decodes a single streamed message into m with the codec,
+// as if data were the body of r without query parameters.
+func DecodeMessage(cdc StreamCodec, r *http.Request, data []byte, m interface{}) error {
+	msgReq := new(http.Request)
+	*msgReq = *r
+	msgReq.URL = &url.URL{Path: r.URL.Path}
+	msgReq.Body = ioutil.NopCloser(bytes.NewReader(data))
+	msgReq.ContentLength = int64(len(data))
+
+	return cdc.ReadRequest(msgReq, m)
+}
+
+// bufferedWriter collects what a codec writes so that it can be framed.
+type bufferedWriter struct {
+	bytes.Buffer
+	header http.Header
+}
+
+func newBufferedWriter() *bufferedWriter {
+	return &bufferedWriter{header: make(http.Header)}
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(int) {}
@@ -0,0 +1,83 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNegotiateStreamType(t *testing.T) {
+	for _, tc := range []struct {
+		accept string
+		want   string
+	}{
+		{"", ContentTypeSSE},
+		{"application/json", ContentTypeSSE},
+		{"text/event-stream", ContentTypeSSE},
+		{"application/x-ndjson", ContentTypeNDJSON},
+		{"application/x-ndjson, text/event-stream", ContentTypeNDJSON},
+		{"text/event-stream, application/x-ndjson", ContentTypeSSE},
+		{"text/event-stream;q=0.5, application/x-ndjson", ContentTypeNDJSON},
+		{"application/x-ndjson;q=0.9, text/event-stream;q=0.8", ContentTypeNDJSON},
+		{"application/x-ndjson;q=0, text/event-stream", ContentTypeSSE},
+		{"application/x-ndjson;q=0", ContentTypeSSE},
+		{"text/event-stream;q=0, application/x-ndjson;q=0.1", ContentTypeNDJSON},
+		{"text/event-stream;q=0, */*", ContentTypeNDJSON},
+		{"application/*, text/event-stream;q=0.5", ContentTypeNDJSON},
+		{"application/*;q=0.2, text/*;q=0.3", ContentTypeSSE},
+		{"*/*;q=0.1, application/x-ndjson;q=0", ContentTypeSSE},
+		{"application/x-ndjson;q=x, text/event-stream;q=0.1", ContentTypeSSE},
+		{"application/x-ndjson; charset=utf-8", ContentTypeNDJSON},
+		{"invalid;;, application/x-ndjson", ContentTypeNDJSON},
+	} {
+		if got := negotiateStreamType(tc.accept); got != tc.want {
+			t.Errorf("%q: want %s, got %s", tc.accept, tc.want, got)
+		}
+	}
+}
+
+// nopCodec writes every message and error as "{}".
+type nopCodec struct{}
+
+func (nopCodec) ReadRequest(r *http.Request, out interface{}) error { return nil }
+
+func (nopCodec) WriteResponse(w http.ResponseWriter, resp interface{}) error {
+	_, err := w.Write([]byte("{}"))
+	return err
+}
+
+func (nopCodec) WriteError(w http.ResponseWriter, err error) error {
+	_, werr := w.Write([]byte("{}"))
+	return werr
+}
+
+func TestServerStreamSendHeader(t *testing.T) {
+	rec := httptest.NewRecorder()
+	stream, err := NewServerStream(rec, httptest.NewRequest(http.MethodPost, "/", nil), nopCodec{}, "/example.Example/ListPeople")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := stream.Context()
+	if method, _ := grpc.Method(ctx); method != "/example.Example/ListPeople" {
+		t.Errorf("want the method of the stream, got %q", method)
+	}
+	if err := grpc.SendHeader(ctx, metadata.Pairs("total", "2")); err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Flushed || rec.Header().Get("Grpc-Metadata-Total") != "2" {
+		t.Fatalf("want the header metadata to be sent at once, got %v", rec.Header())
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs("late", "1")); err == nil {
+		t.Fatal("want an error setting the header metadata once it is sent")
+	}
+
+	grpc.SetTrailer(ctx, metadata.Pairs("cursor", "end"))
+	stream.Finish(nil)
+	if got := rec.Header().Get(http.TrailerPrefix + "Grpc-Trailer-Cursor"); got != "end" {
+		t.Fatalf("want the trailer metadata, got %v", rec.Header())
+	}
+}
@@ -0,0 +1,172 @@
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataTrailerPrefix is prepended to the keys of gRPC trailer metadata sent as HTTP trailers.
+const MetadataTrailerPrefix = "Grpc-Trailer-"
+
+// ServerTransportStream implements grpc.ServerTransportStream, so that server methods
+// can set response metadata with grpc.SetHeader, grpc.SendHeader and grpc.SetTrailer.
+// The header metadata is written as Grpc-Metadata-* response headers and the trailer
+// metadata as Grpc-Trailer-* HTTP trailers.
+type ServerTransportStream struct {
+	method string
+	// sendHeader sends the header metadata of a stream at once, it is nil for unary methods.
+	sendHeader func() error
+
+	mu         sync.Mutex
+	header     metadata.MD
+	trailer    metadata.MD
+	headerSent bool
+}
+
+// NewServerTransportStream returns a copy of ctx carrying a new ServerTransportStream
+// of the method, e.g. "/example.Example/GetPerson".
+func NewServerTransportStream(ctx context.Context, method string) (context.Context, *ServerTransportStream) {
+	s := &ServerTransportStream{method: method}
+	return grpc.NewContextWithServerTransportStream(ctx, s), s
+}
+
+// NewStreamTransportStream returns a ServerTransportStream of the method for a stream,
+// which sends the header metadata with sendHeader when grpc.SendHeader is called.
+// The stream puts it into its context and keeps its metadata there.
+func NewStreamTransportStream(method string, sendHeader func() error) *ServerTransportStream {
+	return &ServerTransportStream{method: method, sendHeader: sendHeader}
+}
+
+// Method returns the full method name, e.g. "/example.Example/GetPerson".
+func (s *ServerTransportStream) Method() string {
+	return s.method
+}
+
+// SetHeader sets the header metadata. It may be called multiple times.
+// It fails if called after the header has been sent.
+func (s *ServerTransportStream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.headerSent {
+		return errors.New("header has already been sent")
+	}
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+// SendHeader sets the header metadata and marks it as sent. Streams send it at once,
+// the response headers of a unary method can only be written along with the response.
+func (s *ServerTransportStream) SendHeader(md metadata.MD) error {
+	s.mu.Lock()
+	if s.headerSent {
+		s.mu.Unlock()
+		return errors.New("header has already been sent")
+	}
+	s.header = metadata.Join(s.header, md)
+	s.headerSent = true
+	s.mu.Unlock()
+
+	if s.sendHeader != nil {
+		return s.sendHeader()
+	}
+	return nil
+}
+
+// SetTrailer sets the trailer metadata which is sent when the response is over.
+// It may be called multiple times.
+func (s *ServerTransportStream) SetTrailer(md metadata.MD) error {
+	s.mu.Lock()
+	s.trailer = metadata.Join(s.trailer, md)
+	s.mu.Unlock()
+	return nil
+}
+
+// Header returns the header metadata set so far.
+func (s *ServerTransportStream) Header() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.header.Copy()
+}
+
+// Trailer returns the trailer metadata set so far.
+func (s *ServerTransportStream) Trailer() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.trailer.Copy()
+}
+
+// SealHeader marks the header as sent and returns the header metadata,
+// so that it cannot be changed once it is being written.
+func (s *ServerTransportStream) SealHeader() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.headerSent = true
+	return s.header.Copy()
+}
+
+// WriteHeader marks the header as sent and adds the header metadata to the headers of w.
+// It must be called before the response is written.
+func (s *ServerTransportStream) WriteHeader(w http.ResponseWriter) {
+	AddMetadata(w.Header(), MetadataHeaderPrefix, s.SealHeader())
+}
+
+// WriteTrailer adds the trailer metadata to w as HTTP trailers. It must be called before
+// the response is written, so that the response is chunked rather than sent with a Content-Length.
+func (s *ServerTransportStream) WriteTrailer(w http.ResponseWriter) {
+	s.mu.Lock()
+	md := s.trailer
+	s.mu.Unlock()
+
+	AddMetadata(w.Header(), http.TrailerPrefix+MetadataTrailerPrefix, md)
+}
+
+// AddMetadata adds the metadata to h, prepending prefix to the keys. Values of binary keys,
+// ending with "-bin", are encoded with base64.
+func AddMetadata(h http.Header, prefix string, md metadata.MD) {
+	for k, vs := range md {
+		name := prefix + textproto.CanonicalMIMEHeaderKey(k)
+		for _, v := range vs {
+			if strings.HasSuffix(k, "-bin") {
+				v = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+			h.Add(name, v)
+		}
+	}
+}
+
+// MetadataFromHeader returns the metadata sent in h with keys starting with prefix,
+// with the prefix removed. Values of binary keys are decoded from base64,
+// and dropped if they cannot be.
+func MetadataFromHeader(h http.Header, prefix string) metadata.MD {
+	md := metadata.MD{}
+	for name, vs := range h {
+		name = textproto.CanonicalMIMEHeaderKey(name)
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, prefix))
+		for _, v := range vs {
+			if strings.HasSuffix(key, "-bin") {
+				b, err := decodeBinHeader(v)
+				if err != nil {
+					continue
+				}
+				v = string(b)
+			}
+			md[key] = append(md[key], v)
+		}
+	}
+	return md
+}
@@ -0,0 +1,308 @@
+// Package websocket serves bidirectional streaming methods over WebSocket connections.
+package websocket
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// CloseStatusOffset is added to a gRPC status code to get the close code
+// the connection is closed with when a method fails.
+const CloseStatusOffset = 4000
+
+// maxCloseReason is the number of bytes left for the reason in a close frame.
+const maxCloseReason = 123
+
+type options struct {
+	pingInterval time.Duration
+	pongWait     time.Duration
+	writeWait    time.Duration
+	checkOrigin  func(r *http.Request) bool
+}
+
+// Option configures a ServerStream.
+type Option func(*options)
+
+// WithPingInterval sets how often the server pings the client. Zero disables pings.
+func WithPingInterval(d time.Duration) Option {
+	return func(opts *options) {
+		opts.pingInterval = d
+	}
+}
+
+// WithPongWait sets how long the server waits for a message or a pong from the client
+// before the connection is considered dead. It should be longer than the ping interval.
+func WithPongWait(d time.Duration) Option {
+	return func(opts *options) {
+		opts.pongWait = d
+	}
+}
+
+// WithWriteWait sets the time limit for writing a message to the client.
+func WithWriteWait(d time.Duration) Option {
+	return func(opts *options) {
+		opts.writeWait = d
+	}
+}
+
+// WithCheckOrigin sets the function validating the Origin header of the handshake.
+// By default cross-origin requests are rejected.
+func WithCheckOrigin(f func(r *http.Request) bool) Option {
+	return func(opts *options) {
+		opts.checkOrigin = f
+	}
+}
+
+// ServerStream implements grpc.ServerStream on top of a WebSocket connection.
+// Every message, both sent and received, is a single frame encoded with the codec.
+// The connection is upgraded lazily, so that header metadata set before
+// the first message is sent along with the handshake response.
+type ServerStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	r      *http.Request
+	w      http.ResponseWriter
+	cdc    runtime.StreamCodec
+	opts   options
+	// transport holds the metadata of the stream, it is carried by the context.
+	transport *runtime.ServerTransportStream
+
+	mu       sync.Mutex
+	conn     *gorilla.Conn
+	upgraded bool
+	err      error
+}
+
+// NewServerStream returns a stream for a bidirectional streaming method, named by its full
+// method name, e.g. "/example.Example/Chat".
+// The stream is canceled when the connection fails or the client goes away.
+// It fails if the codec is a runtime.ClientStreamChecker rejecting the request,
+// the error is then written with the codec instead of upgrading the connection.
+func NewServerStream(w http.ResponseWriter, r *http.Request, cdc runtime.StreamCodec, method string, opts ...Option) (*ServerStream, error) {
+	if err := runtime.CheckClientStream(cdc, r); err != nil {
+		return nil, err
+	}
+
+	o := options{
+		pingInterval: 30 * time.Second,
+		pongWait:     60 * time.Second,
+		writeWait:    10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	s := &ServerStream{
+		cancel: cancel,
+		r:      r,
+		w:      w,
+		cdc:    cdc,
+		opts:   o,
+	}
+	s.transport = runtime.NewStreamTransportStream(method, func() error {
+		_, err := s.upgrade()
+		return err
+	})
+	s.ctx = grpc.NewContextWithServerTransportStream(ctx, s.transport)
+	return s, nil
+}
+
+// Context returns the context of the stream.
+func (s *ServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// SetHeader sets the header metadata. It may be called multiple times.
+// It fails if called after the connection has been upgraded.
+func (s *ServerStream) SetHeader(md metadata.MD) error {
+	return s.transport.SetHeader(md)
+}
+
+// SendHeader upgrades the connection sending the header metadata along with the handshake response.
+func (s *ServerStream) SendHeader(md metadata.MD) error {
+	return s.transport.SendHeader(md)
+}
+
+// SetTrailer sets the trailer metadata. WebSocket has no means to send it, so it is dropped.
+func (s *ServerStream) SetTrailer(md metadata.MD) {
+	s.transport.SetTrailer(md)
+}
+
+// SendMsg encodes m with the codec and sends it as a text frame.
+func (s *ServerStream) SendMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	conn, err := s.upgrade()
+	if err != nil {
+		return err
+	}
+
+	data, err := runtime.EncodeMessage(s.cdc, m)
+	if err != nil {
+		return err
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(s.opts.writeWait))
+	if err := conn.WriteMessage(gorilla.TextMessage, data); err != nil {
+		s.cancel()
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	return nil
+}
+
+// RecvMsg decodes the next frame into m with the codec.
+// It returns io.EOF when the client closes the connection normally.
+func (s *ServerStream) RecvMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	conn, err := s.upgrade()
+	if err != nil {
+		return err
+	}
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		s.cancel()
+		if gorilla.IsCloseError(err, gorilla.CloseNormalClosure, gorilla.CloseGoingAway) {
+			return io.EOF
+		}
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if s.opts.pingInterval > 0 {
+		conn.SetReadDeadline(time.Now().Add(s.opts.pongWait))
+	}
+
+	return runtime.DecodeMessage(s.cdc, s.r.WithContext(s.ctx), data, m)
+}
+
+// Finish completes the stream with the error returned by the server method.
+// An error occurred before the connection has been upgraded is written with
+// the codec as an ordinary response. Otherwise the error is sent as a frame
+// and the connection is closed with the code mapped by CloseCode.
+func (s *ServerStream) Finish(err error) {
+	defer s.cancel()
+
+	s.mu.Lock()
+	upgraded := s.upgraded
+	s.mu.Unlock()
+
+	if !upgraded && err != nil {
+		s.cdc.WriteError(s.w, err)
+		return
+	}
+
+	conn, upgradeErr := s.upgrade()
+	if upgradeErr != nil {
+		return
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.opts.writeWait)
+	if err != nil {
+		conn.SetWriteDeadline(deadline)
+		conn.WriteMessage(gorilla.TextMessage, runtime.EncodeError(s.cdc, err))
+	}
+	conn.WriteControl(gorilla.CloseMessage, gorilla.FormatCloseMessage(CloseCode(err), closeReason(err)), deadline)
+}
+
+// upgrade upgrades the connection once and starts pinging the client.
+func (s *ServerStream) upgrade() (*gorilla.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.upgraded {
+		return s.conn, s.err
+	}
+	s.upgraded = true
+
+	h := make(http.Header)
+	runtime.AddMetadata(h, runtime.MetadataHeaderPrefix, s.transport.SealHeader())
+
+	upgrader := gorilla.Upgrader{CheckOrigin: s.opts.checkOrigin}
+	conn, err := upgrader.Upgrade(s.w, s.r, h)
+	if err != nil {
+		// the upgrader has already replied with an HTTP error
+		s.cancel()
+		s.err = status.Error(codes.InvalidArgument, err.Error())
+		return nil, s.err
+	}
+	s.conn = conn
+
+	if s.opts.pingInterval > 0 {
+		conn.SetReadDeadline(time.Now().Add(s.opts.pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(s.opts.pongWait))
+		})
+		go s.ping(conn)
+	}
+
+	return conn, nil
+}
+
+// ping pings the client until the stream is done.
+func (s *ServerStream) ping(conn *gorilla.Conn) {
+	ticker := time.NewTicker(s.opts.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(gorilla.PingMessage, nil, time.Now().Add(s.opts.writeWait)); err != nil {
+				s.cancel()
+				return
+			}
+		}
+	}
+}
+
+// CloseCode returns the WebSocket close code for an error returned by a method:
+// 1000 (normal closure) for nil, otherwise CloseStatusOffset plus the gRPC status code,
+// e.g. 4005 for codes.NotFound.
+func CloseCode(err error) int {
+	if err == nil {
+		return gorilla.CloseNormalClosure
+	}
+	return CloseStatusOffset + int(fromError(err).Code())
+}
+
+func closeReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	reason := fromError(err).Message()
+	if len(reason) <= maxCloseReason {
+		return reason
+	}
+	// cut on a rune boundary, the reason must be valid UTF-8
+	n := maxCloseReason
+	for n > 0 && !utf8.RuneStart(reason[n]) {
+		n--
+	}
+	return reason[:n]
+}
+
+func fromError(err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+	return status.New(codes.Unknown, err.Error())
+}
@@ -0,0 +1,241 @@
+package websocket
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/lazada/protoc-gen-go-http/codec"
+	"github.com/lazada/protoc-gen-go-http/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// jsonCodec encodes messages with encoding/json, and errors as {"error": "..."}.
+type jsonCodec struct{}
+
+func (jsonCodec) ReadRequest(r *http.Request, out interface{}) error {
+	return json.NewDecoder(r.Body).Decode(out)
+}
+
+func (jsonCodec) WriteResponse(w http.ResponseWriter, resp interface{}) error {
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func (jsonCodec) WriteError(w http.ResponseWriter, err error) error {
+	w.WriteHeader(http.StatusInternalServerError)
+	return json.NewEncoder(w).Encode(map[string]string{"error": status.Convert(err).Message()})
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+// serve serves a bidirectional streaming method implemented by method over WebSocket.
+func serve(t *testing.T, method func(stream grpc.ServerStream) error) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream, err := NewServerStream(w, r, jsonCodec{}, "/test.Chat/Talk", WithPingInterval(0))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		stream.Finish(method(stream))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server) (*gorilla.Conn, *http.Response) {
+	conn, resp, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn, resp
+}
+
+func TestServerStreamEcho(t *testing.T) {
+	srv := serve(t, func(stream grpc.ServerStream) error {
+		if grpc.ServerTransportStreamFromContext(stream.Context()).Method() != "/test.Chat/Talk" {
+			return status.Error(codes.Internal, "unexpected method")
+		}
+		if err := grpc.SetHeader(stream.Context(), metadata.Pairs("session", "1")); err != nil {
+			return err
+		}
+		for {
+			var m message
+			err := stream.RecvMsg(&m)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			m.Text = strings.ToUpper(m.Text)
+			if err := stream.SendMsg(&m); err != nil {
+				return err
+			}
+		}
+	})
+
+	conn, resp := dial(t, srv)
+	if got := resp.Header.Get(runtime.MetadataHeaderPrefix + "Session"); got != "1" {
+		t.Errorf("want the session header metadata in the handshake response, got %q", got)
+	}
+
+	for _, text := range []string{"hello", "world"} {
+		if err := conn.WriteJSON(&message{Text: text}); err != nil {
+			t.Fatal(err)
+		}
+		var m message
+		if err := conn.ReadJSON(&m); err != nil {
+			t.Fatal(err)
+		}
+		if want := strings.ToUpper(text); m.Text != want {
+			t.Fatalf("want %q, got %q", want, m.Text)
+		}
+	}
+
+	if err := conn.WriteMessage(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, "")); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := conn.ReadMessage()
+	if !gorilla.IsCloseError(err, gorilla.CloseNormalClosure) {
+		t.Fatalf("want a normal closure, got %v", err)
+	}
+}
+
+func TestServerStreamError(t *testing.T) {
+	srv := serve(t, func(stream grpc.ServerStream) error {
+		var m message
+		if err := stream.RecvMsg(&m); err != nil {
+			return err
+		}
+		return status.Errorf(codes.NotFound, "no %s", m.Text)
+	})
+
+	conn, _ := dial(t, srv)
+	if err := conn.WriteJSON(&message{Text: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var e struct{ Error string }
+	if err := conn.ReadJSON(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Error != "no bob" {
+		t.Errorf("want the error frame, got %+v", e)
+	}
+
+	_, _, err := conn.ReadMessage()
+	closeErr, ok := err.(*gorilla.CloseError)
+	if !ok {
+		t.Fatalf("want a close error, got %v", err)
+	}
+	if closeErr.Code != CloseStatusOffset+int(codes.NotFound) || closeErr.Text != "no bob" {
+		t.Fatalf("want close code %d with the error message, got %d %q", CloseStatusOffset+int(codes.NotFound), closeErr.Code, closeErr.Text)
+	}
+}
+
+func TestServerStreamErrorBeforeUpgrade(t *testing.T) {
+	srv := serve(t, func(stream grpc.ServerStream) error {
+		return status.Error(codes.PermissionDenied, "denied")
+	})
+
+	_, resp, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err == nil {
+		t.Fatal("want the handshake to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("want the error written by the codec, got %v", resp)
+	}
+}
+
+func TestServerStreamNotWebSocket(t *testing.T) {
+	srv := serve(t, func(stream grpc.ServerStream) error {
+		var m message
+		err := stream.RecvMsg(&m)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("want an InvalidArgument error, got %v", err)
+		}
+		return err
+	})
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("want the upgrader to reject the request, got %s", resp.Status)
+	}
+}
+
+// TestServerStreamJsonRPC checks that the handshake is answered with E_INVALID_REQ under JSON-RPC,
+// which cannot carry the request messages of bidirectional streams.
+func TestServerStreamJsonRPC(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cdc := codec.NewJsonRPCCodec()
+		stream, err := NewServerStream(w, r, cdc, "/test.Chat/Talk", WithPingInterval(0))
+		if err != nil {
+			cdc.WriteError(w, err)
+			return
+		}
+		t.Error("want the stream to be rejected")
+		stream.Finish(nil)
+	}))
+	t.Cleanup(srv.Close)
+
+	_, resp, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err == nil {
+		t.Fatal("want the handshake to fail")
+	}
+	if resp == nil {
+		t.Fatalf("want the error written by the codec, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	var got struct {
+		Error struct {
+			Code int64 `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Error.Code != codec.E_INVALID_REQ {
+		t.Fatalf("want error %d, got %d", codec.E_INVALID_REQ, got.Error.Code)
+	}
+}
+
+func TestCloseCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{nil, gorilla.CloseNormalClosure},
+		{status.Error(codes.NotFound, "x"), 4005},
+		{status.Error(codes.Unauthenticated, "x"), 4016},
+		{io.ErrUnexpectedEOF, 4002},
+	} {
+		if got := CloseCode(tc.err); got != tc.want {
+			t.Errorf("%v: want %d, got %d", tc.err, tc.want, got)
+		}
+	}
+}
+
+func TestCloseReason(t *testing.T) {
+	long := strings.Repeat("é", 100)
+	reason := closeReason(status.Error(codes.Internal, long))
+	if len(reason) > maxCloseReason || !strings.HasPrefix(long, reason) {
+		t.Fatalf("want a prefix of the message of at most %d bytes, got %d bytes", maxCloseReason, len(reason))
+	}
+}